@@ -0,0 +1,30 @@
+package ghupdate
+
+import "fmt"
+
+// ApplyStrategy selects how ApplyUpdate installs a prepared update.
+type ApplyStrategy string
+
+const (
+	// ApplyStrategyHelper starts the staged update as a helper process, which waits for the
+	// application to exit, replaces the executable and continues as the application (see
+	// HandleUpdateMode). It works on every platform and is the default.
+	ApplyStrategyHelper ApplyStrategy = "helper"
+	// ApplyStrategyInPlace replaces the executable from the running process by writing the new one
+	// next to it and renaming it over the original, then ApplyUpdate re-executes it with the same
+	// arguments, environment and working directory. No helper process is spawned and nothing waits
+	// for the application to exit. On Unix, the running process keeps the old file open through its
+	// inode and the re-executed process keeps its PID. On Windows, the running executable is moved
+	// aside to ".old" first.
+	ApplyStrategyInPlace ApplyStrategy = "in-place"
+)
+
+// validateApplyStrategy checks that strategy is one of the ApplyStrategy constants.
+// It returns an error for unknown strategies.
+func validateApplyStrategy(strategy ApplyStrategy) error {
+	switch strategy {
+	case "", ApplyStrategyHelper, ApplyStrategyInPlace:
+		return nil
+	}
+	return fmt.Errorf("unknown ApplyStrategy %q", strategy)
+}
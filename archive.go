@@ -0,0 +1,359 @@
+package ghupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxExtractedSize caps the size of an executable extracted from an archive, guarding against
+// decompression bombs.
+const maxExtractedSize = 2 << 30
+
+// errBinaryNotFound is returned when an archive holds no entry identifiable as the executable.
+var errBinaryNotFound = errors.New("executable not found in archive")
+
+// archiveSuffixes maps asset name suffixes to archive formats, longest suffixes first. Formats
+// starting with "tar." are compressed tar archives, "zip" is a zip archive, and the others are
+// a single compressed executable.
+var archiveSuffixes = []struct{ suffix, format string }{
+	{".tar.gz", "tar.gz"}, {".tgz", "tar.gz"},
+	{".tar.bz2", "tar.bz2"}, {".tbz2", "tar.bz2"}, {".tbz", "tar.bz2"},
+	{".tar.xz", "tar.xz"}, {".txz", "tar.xz"},
+	{".tar.zst", "tar.zst"}, {".tzst", "tar.zst"},
+	{".zip", "zip"},
+	{".gz", "gz"}, {".bz2", "bz2"}, {".xz", "xz"}, {".zst", "zst"},
+}
+
+// archiveFormat returns the archive format of an asset, judging by its name (see archiveSuffixes),
+// or an empty string if the asset is not a known archive or compressed file.
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format
+		}
+	}
+	return ""
+}
+
+// decompressionTools maps the compressions the standard library has no decoder for to the external
+// tool decompressing them (see decompress).
+var decompressionTools = map[string]string{"xz": "xz", "zst": "zstd"}
+
+// canDecompress reports whether assets in format (see archiveFormat) can be installed on this
+// machine: always for formats the standard library decodes, and for xz and Zstandard only if the
+// xz or zstd tool is installed.
+func canDecompress(format string) bool {
+	tool, ok := decompressionTools[strings.TrimPrefix(format, "tar.")]
+	return !ok || toolInstalled(tool)
+}
+
+// toolInstalled reports whether the external tool is found in PATH.
+func toolInstalled(tool string) bool {
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+// isSingleFileFormat reports whether format denotes a single compressed file rather than an archive.
+func isSingleFileFormat(format string) bool {
+	return format != "zip" && !strings.HasPrefix(format, "tar.")
+}
+
+// extractArchiveBinary extracts the executable from the archive at archivePath to destPath, created
+// with the configured FileMode. The executable is the entry matching config.BinaryPathInArchive if
+// set, otherwise the entry named like the executable (see archiveBinaryName), or failing that the
+// only executable file of the archive. The archive must be in the given format (see archiveFormat)
+// and hold the given release version.
+//
+// It returns an error if the archive cannot be read, holds no identifiable executable, or the
+// executable exceeds maxExtractedSize.
+func extractArchiveBinary(config UpdateConfig, archivePath, format, destPath, version string) error {
+	if isSingleFileFormat(format) {
+		return extractFromArchive(config, archivePath, format, destPath, nil)
+	}
+
+	pattern := ""
+	if config.BinaryPathInArchive != "" {
+		pattern = expandArchivePath(config, config.BinaryPathInArchive, version)
+	}
+	match := func(name string, mode os.FileMode) bool {
+		if pattern != "" {
+			matched, _ := path.Match(pattern, name)
+			return matched
+		}
+		return path.Base(name) == archiveBinaryName(config)
+	}
+
+	err := extractFromArchive(config, archivePath, format, destPath, match)
+	if !errors.Is(err, errBinaryNotFound) || config.BinaryPathInArchive != "" {
+		return err
+	}
+
+	// Fall back to the only executable file, e.g. an archive naming its binary after the project
+	var executables []string
+	if err := extractFromArchive(config, archivePath, format, "", func(name string, mode os.FileMode) bool {
+		if mode&0o111 != 0 || strings.EqualFold(path.Ext(name), ".exe") {
+			executables = append(executables, name)
+		}
+		return false
+	}); err != nil && !errors.Is(err, errBinaryNotFound) {
+		return err
+	}
+	if len(executables) != 1 {
+		return fmt.Errorf("%w: no entry is named %s; set BinaryPathInArchive", errBinaryNotFound, archiveBinaryName(config))
+	}
+	return extractFromArchive(config, archivePath, format, destPath, func(name string, mode os.FileMode) bool {
+		return name == executables[0]
+	})
+}
+
+// expandArchivePath expands the placeholders of an archive entry pattern such as BinaryPathInArchive
+// for version on the target platform: those of AssetPattern with GOOS and GOARCH names (see
+// buildAssetName), and {name}, the executable name without extension.
+func expandArchivePath(config UpdateConfig, pattern, version string) string {
+	targetOS, targetArch := resolvePlatform(config)
+	pattern = buildAssetName(pattern, version, targetOS, targetArch, targetOS)
+	return strings.ReplaceAll(pattern, "{name}", strings.TrimSuffix(archiveBinaryName(config), ".exe"))
+}
+
+// archiveBinaryName returns the file name the executable is expected to have inside an archive:
+// the name of ExecutablePath (or GitHubRepo if unset), with ".exe" for Windows targets.
+func archiveBinaryName(config UpdateConfig) string {
+	name := config.GitHubRepo
+	if config.ExecutablePath != "" {
+		name = strings.TrimSuffix(filepath.Base(config.ExecutablePath), ".exe")
+	}
+	if targetOS, _ := resolvePlatform(config); targetOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// extractFromArchive walks the regular files of the archive at archivePath and extracts the first
+// one accepted by match to destPath. Entry names are cleaned and relative, with forward slashes;
+// entries with absolute names or names escaping the archive root ("zip slip") are ignored, and the
+// entry is only ever written to destPath. If destPath is empty, nothing is extracted and match only
+// observes the entries. Single compressed files are decompressed to destPath as a whole.
+//
+// It returns errBinaryNotFound if no entry is accepted, or an error if the archive cannot be read
+// or the entry cannot be written.
+func extractFromArchive(config UpdateConfig, archivePath, format, destPath string, match func(name string, mode os.FileMode) bool) error {
+	if format == "zip" {
+		return extractFromZip(config, archivePath, destPath, match)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	r, err := decompress(strings.TrimPrefix(format, "tar."), f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if isSingleFileFormat(format) {
+		return writeExtracted(config, r, destPath)
+	}
+	return extractFromTar(config, r, destPath, match)
+}
+
+// decompress returns a reader decompressing r, compressed with gzip ("gz"), bzip2 ("bz2"), xz ("xz")
+// or Zstandard ("zst"). The standard library has no xz and Zstandard decoders, so those are
+// decompressed by the xz and zstd tools (see decompressionTools), which must be installed; assets
+// in those formats are not chosen on machines without them (see canDecompress).
+//
+// It returns an error if the compression is unsupported or its tool cannot be started.
+func decompress(compression string, r io.Reader) (io.ReadCloser, error) {
+	switch compression {
+	case "gz":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		return gz, nil
+	case "bz2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case "xz", "zst":
+		return decompressCommand(decompressionTools[compression], r)
+	}
+	return nil, fmt.Errorf("unsupported compression %q", compression)
+}
+
+// commandReader reads the output of a decompression tool, reporting its failure at the end of the stream.
+type commandReader struct {
+	stdout io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+	done   bool
+}
+
+// decompressCommand starts tool (xz or zstd) to decompress r.
+//
+// It returns an error if the tool is not installed or cannot be started.
+func decompressCommand(tool string, r io.Reader) (io.ReadCloser, error) {
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("the %s tool is required to decompress %s assets: %w", tool, tool, err)
+	}
+	c := &commandReader{cmd: exec.Command(tool, "-d", "-c")}
+	c.cmd.Stdin = r
+	c.cmd.Stderr = &c.stderr
+	stdout, err := c.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	c.stdout = stdout
+	if err := c.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", tool, err)
+	}
+	return c, nil
+}
+
+// Read reads decompressed data. At the end of the stream, it waits for the tool and reports its failure.
+func (c *commandReader) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF && !c.done {
+		c.done = true
+		if waitErr := c.cmd.Wait(); waitErr != nil {
+			return n, fmt.Errorf("%s failed: %w: %s", filepath.Base(c.cmd.Path), waitErr, strings.TrimSpace(c.stderr.String()))
+		}
+	}
+	return n, err
+}
+
+// Close stops the tool if the stream was not read to its end.
+func (c *commandReader) Close() error {
+	if !c.done {
+		c.done = true
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	}
+	return nil
+}
+
+// extractFromTar extracts the first regular file of a tar stream accepted by match (see extractFromArchive).
+func extractFromTar(config UpdateConfig, r io.Reader, destPath string, match func(name string, mode os.FileMode) bool) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return errBinaryNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := cleanArchivePath(header.Name)
+		if name == "" || !match(name, header.FileInfo().Mode()) {
+			continue
+		}
+		return writeExtracted(config, tr, destPath)
+	}
+}
+
+// extractFromZip extracts the first regular file of a zip archive accepted by match (see extractFromArchive).
+func extractFromZip(config UpdateConfig, archivePath, destPath string, match func(name string, mode os.FileMode) bool) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		name := cleanArchivePath(file.Name)
+		if name == "" || !match(name, file.Mode()) {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from zip archive: %w", file.Name, err)
+		}
+		defer rc.Close()
+		return writeExtracted(config, rc, destPath)
+	}
+	return errBinaryNotFound
+}
+
+// cleanArchivePath normalizes an archive entry name to a clean relative path with forward slashes.
+// It returns an empty string for absolute names and names escaping the archive root.
+func cleanArchivePath(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return ""
+	}
+	name = path.Clean(name)
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return ""
+	}
+	return name
+}
+
+// writeExtracted writes an extracted entry to destPath with the configured FileMode, up to maxExtractedSize bytes.
+func writeExtracted(config UpdateConfig, r io.Reader, destPath string) error {
+	out, err := openStagedFile(config, destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", destPath, err)
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, io.LimitReader(r, maxExtractedSize+1))
+	if err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to extract executable: %w", err)
+	}
+	if n > maxExtractedSize {
+		os.Remove(destPath)
+		return fmt.Errorf("extracted executable exceeds %d bytes", int64(maxExtractedSize))
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to write %q: %w", destPath, err)
+	}
+	return nil
+}
+
+// extractionPath returns the temporary path an executable is extracted to before replacing the
+// archive at updatePath.
+func extractionPath(updatePath string) string {
+	return updatePath + ".extract"
+}
+
+// extractStagedArchive replaces the archive of release version downloaded to updatePath by the
+// executable it contains, and stages the auxiliary files declared in config.ArchiveFiles.
+//
+// It returns an error if extraction fails; the archive is removed in that case.
+func extractStagedArchive(config UpdateConfig, updatePath, format, version string) error {
+	extractPath := extractionPath(updatePath)
+	if err := extractArchiveBinary(config, updatePath, format, extractPath, version); err != nil {
+		os.Remove(updatePath)
+		return err
+	}
+	if err := stageArchiveFiles(config, updatePath, format, version); err != nil {
+		os.Remove(extractPath)
+		os.Remove(updatePath)
+		return err
+	}
+	if err := os.Rename(extractPath, updatePath); err != nil {
+		os.Remove(extractPath)
+		os.Remove(updatePath)
+		return fmt.Errorf("failed to move extracted executable into place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package ghupdate
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanArchivePath(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"myapp", "myapp"},
+		{"myapp-v1.2.3/bin/myapp", "myapp-v1.2.3/bin/myapp"},
+		{"./bin//myapp", "bin/myapp"},
+		{"bin\\myapp", "bin/myapp"},
+		{"a/b/../myapp", "a/myapp"},
+		{"../x", ""},
+		{"a/../../x", ""},
+		{"..", ""},
+		{".", ""},
+		{"/abs", ""},
+		{"/abs/../x", ""},
+		{"C:\\x", ""},
+		{"C:x", ""},
+		{"..\\x", ""},
+		{"\\\\server\\share\\x", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanArchivePath(tt.name); got != tt.want {
+			t.Errorf("cleanArchivePath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractArchiveBinaryIgnoresEscapingEntries(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "work", "myapp.zip")
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries escaping the archive root come first and are named like the executable, so that
+	// they would be chosen if their names were not rejected
+	entries := []struct{ name, content string }{
+		{"../myapp", "escape"},
+		{"a/../../myapp", "escape"},
+		{"/tmp/myapp", "absolute"},
+		{"C:\\myapp", "drive"},
+		{"..\\myapp", "escape"},
+		{"myapp-v1.2.3/bin/myapp", "binary"},
+		{"myapp-v1.2.3/README.md", "readme"},
+	}
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entry.content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	config := UpdateConfig{GitHubRepo: "myapp", OS: "linux", Arch: "amd64"}
+	destPath := filepath.Join(dir, "work", "myapp.extract")
+	if err := extractArchiveBinary(config, archivePath, "zip", destPath, "v1.2.3"); err != nil {
+		t.Fatalf("extractArchiveBinary: %v", err)
+	}
+	data, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "binary" {
+		t.Errorf("extracted %q, want the content of myapp-v1.2.3/bin/myapp", data)
+	}
+
+	// Nothing but the archive and the extracted executable may have been written
+	var written []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			written = append(written, path)
+		}
+		return nil
+	})
+	if len(written) != 2 {
+		t.Errorf("files after extraction = %q, want only %s and %s", written, archivePath, destPath)
+	}
+}
@@ -0,0 +1,231 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// archiveFilesDirName is the directory of the data directory holding auxiliary files staged
+	// from an archive asset until the update is applied.
+	archiveFilesDirName = "update-files"
+	// archiveFilesManifestName lists the staged auxiliary files and their destinations.
+	archiveFilesManifestName = "files.json"
+	// defaultArchiveFileMode is the permission of installed auxiliary files when ArchiveFile.Mode is unset.
+	defaultArchiveFileMode os.FileMode = 0644
+)
+
+// ArchiveFile declares a file to install from archive assets besides the executable, such as shell
+// completions, man pages or a license.
+type ArchiveFile struct {
+	// Source is the path.Match pattern of the file inside the archive. It supports the placeholders
+	// of BinaryPathInArchive, e.g. "{name}-{version_no_v}/completions/{name}.bash".
+	Source string
+	// Destination is the path the file is installed to. Relative paths are resolved against the
+	// directory of ExecutablePath.
+	Destination string
+	// Mode is the permission of the installed file. If zero, 0644 is used.
+	Mode os.FileMode
+	// Optional skips the file if the archive holds none matching Source, instead of failing the update.
+	Optional bool
+}
+
+// archiveFilesManifest lists the auxiliary files staged for an update.
+type archiveFilesManifest struct {
+	Version string              `json:"version"`
+	Files   []stagedArchiveFile `json:"files"`
+}
+
+// stagedArchiveFile is an auxiliary file staged in the archive files directory.
+type stagedArchiveFile struct {
+	Name        string      `json:"name"`
+	Destination string      `json:"destination"`
+	Mode        os.FileMode `json:"mode"`
+}
+
+// archiveFilesDir returns the directory auxiliary files are staged in.
+func archiveFilesDir(dataDir string) string {
+	return filepath.Join(dataDir, archiveFilesDirName)
+}
+
+// stageArchiveFiles extracts the files declared in config.ArchiveFiles from the archive of release
+// version at archivePath to the archive files directory, replacing files staged for an earlier
+// update, and records their destinations for HandleUpdateMode.
+//
+// It returns an error if a declared file is invalid or missing without being optional, the asset is
+// not an archive, or extraction fails; nothing is staged in that case.
+func stageArchiveFiles(config UpdateConfig, archivePath, format, version string) error {
+	dir := archiveFilesDir(config.DataDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove previously staged files: %w", err)
+	}
+	if len(config.ArchiveFiles) == 0 {
+		return nil
+	}
+	if isSingleFileFormat(format) {
+		return fmt.Errorf("ArchiveFiles requires a tar or zip archive asset")
+	}
+	if err := os.MkdirAll(dir, dirMode(config)); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", dir, err)
+	}
+
+	staged, err := extractArchiveFiles(config, archivePath, format, version, dir)
+	if err == nil {
+		err = writeArchiveFilesManifest(config, dir, archiveFilesManifest{Version: version, Files: staged})
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	return nil
+}
+
+// extractArchiveFiles extracts the files declared in config.ArchiveFiles to dir (see stageArchiveFiles).
+func extractArchiveFiles(config UpdateConfig, archivePath, format, version, dir string) ([]stagedArchiveFile, error) {
+	var staged []stagedArchiveFile
+	for i, file := range config.ArchiveFiles {
+		if file.Source == "" || file.Destination == "" {
+			return nil, fmt.Errorf("invalid ArchiveFiles entry %d: Source and Destination are required", i)
+		}
+		destination := file.Destination
+		if !filepath.IsAbs(destination) {
+			destination = filepath.Join(filepath.Dir(config.ExecutablePath), destination)
+		}
+		mode := file.Mode.Perm()
+		if mode == 0 {
+			mode = defaultArchiveFileMode
+		}
+
+		pattern := expandArchivePath(config, file.Source, version)
+		name := strconv.Itoa(i)
+		err := extractFromArchive(config, archivePath, format, filepath.Join(dir, name), func(entry string, _ os.FileMode) bool {
+			matched, _ := path.Match(pattern, entry)
+			return matched
+		})
+		if errors.Is(err, errBinaryNotFound) {
+			if file.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("archive holds no file matching %s", pattern)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", pattern, err)
+		}
+		staged = append(staged, stagedArchiveFile{Name: name, Destination: destination, Mode: mode})
+	}
+	return staged, nil
+}
+
+// writeArchiveFilesManifest records the staged auxiliary files in dir, with the configured file
+// mode less the execute bits.
+func writeArchiveFilesManifest(config UpdateConfig, dir string, manifest archiveFilesManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode staged files: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, archiveFilesManifestName), data, fileMode(config)&^0111); err != nil {
+		return fmt.Errorf("failed to record staged files: %w", err)
+	}
+	return nil
+}
+
+// pendingArchiveFile is a staged auxiliary file copied next to its destination, ready to be
+// moved into place.
+type pendingArchiveFile struct {
+	tmpPath     string
+	destination string
+}
+
+// prepareArchiveFiles copies the auxiliary files staged in config.DataDir next to their destinations,
+// so that installing them after the executable has been replaced only takes a rename per file.
+// It returns no files if none are staged, or if they were staged for another version than the
+// staged executable, e.g. when a plain executable was staged after an archive.
+//
+// It returns an error if the staged files cannot be read or copied; copies made so far are removed.
+func prepareArchiveFiles(config UpdateConfig) ([]pendingArchiveFile, error) {
+	dataDir := config.DataDir
+	dir := archiveFilesDir(dataDir)
+	data, err := os.ReadFile(filepath.Join(dir, archiveFilesManifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged files: %w", err)
+	}
+	var manifest archiveFilesManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode staged files: %w", err)
+	}
+	if manifest.Version != stagedVersion(dataDir) {
+		os.RemoveAll(dir)
+		return nil, nil
+	}
+
+	var pending []pendingArchiveFile
+	for _, file := range manifest.Files {
+		tmpPath := file.Destination + ".new"
+		if err := copyArchiveFile(filepath.Join(dir, file.Name), tmpPath, file.Mode, dirMode(config)); err != nil {
+			abortArchiveFiles(pending)
+			return nil, err
+		}
+		pending = append(pending, pendingArchiveFile{tmpPath: tmpPath, destination: file.Destination})
+	}
+	return pending, nil
+}
+
+// copyArchiveFile copies a staged auxiliary file to dst with the given permissions, creating the
+// directories leading to it with dirPerm.
+func copyArchiveFile(src, dst string, mode, dirPerm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), dirPerm); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", dst, err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open staged file %q: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to copy %q: %w", dst, err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to write %q: %w", dst, err)
+	}
+	return nil
+}
+
+// installArchiveFiles moves auxiliary files prepared by prepareArchiveFiles into place and removes
+// the staged files from dataDir.
+//
+// It returns an error if a file cannot be moved into place; the remaining files are still installed.
+func installArchiveFiles(dataDir string, pending []pendingArchiveFile) error {
+	var errs []error
+	for _, file := range pending {
+		if err := os.Rename(file.tmpPath, file.destination); err != nil {
+			os.Remove(file.tmpPath)
+			errs = append(errs, fmt.Errorf("failed to install %q: %w", file.destination, err))
+		}
+	}
+	os.RemoveAll(archiveFilesDir(dataDir))
+	return errors.Join(errs...)
+}
+
+// abortArchiveFiles removes auxiliary files prepared by prepareArchiveFiles.
+func abortArchiveFiles(pending []pendingArchiveFile) {
+	for _, file := range pending {
+		os.Remove(file.tmpPath)
+	}
+}
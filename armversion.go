@@ -0,0 +1,71 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// envARM overrides the detected ARM version when UpdateConfig.ARMVersion is zero.
+const envARM = "GHUPDATE_ARM"
+
+// minARMVersion is the oldest ARM version Go builds for (GOARM=5).
+const minARMVersion = 5
+
+// defaultARMVersion is used for 32-bit ARM targets whose version cannot be determined. It is Go's
+// default GOARM when cross-compiling.
+const defaultARMVersion = 7
+
+// resolveARMVersion determines the ARM architecture version (5, 6 or 7) for a 32-bit ARM target.
+// The ARMVersion config field takes precedence, followed by the GHUPDATE_ARM environment variable,
+// the version of the running CPU when the target is the running platform, the GOARM setting the
+// binary was built with, and finally defaultARMVersion. It returns 0 for other architectures.
+func resolveARMVersion(config UpdateConfig, targetOS, targetArch string) int {
+	if targetArch != "arm" {
+		return 0
+	}
+	if config.ARMVersion > 0 {
+		return config.ARMVersion
+	}
+	if v, err := strconv.Atoi(strings.TrimPrefix(os.Getenv(envARM), "v")); err == nil && v > 0 {
+		return v
+	}
+	if targetOS == runtime.GOOS && runtime.GOARCH == "arm" {
+		if v, ok := detectARMVersion(); ok {
+			return v
+		}
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			if setting.Key != "GOARM" {
+				continue
+			}
+			// The setting may carry a float ABI suffix, e.g. "7,softfloat"
+			value, _, _ := strings.Cut(setting.Value, ",")
+			if v, err := strconv.Atoi(value); err == nil {
+				return v
+			}
+		}
+	}
+	return defaultARMVersion
+}
+
+// armVersionNames returns the {armversion} values to try for the target: "v7", "v6" and so on down
+// to minARMVersion for a 32-bit ARM target, as its CPU runs binaries built for older versions, and
+// an empty string for other architectures. Only the exact version is returned if fallbacks is false.
+func armVersionNames(config UpdateConfig, targetOS, targetArch string, fallbacks bool) []string {
+	version := resolveARMVersion(config, targetOS, targetArch)
+	if version == 0 {
+		return []string{""}
+	}
+	names := []string{fmt.Sprintf("v%d", version)}
+	if fallbacks {
+		for v := min(version, defaultARMVersion+1) - 1; v >= minARMVersion; v-- {
+			names = append(names, fmt.Sprintf("v%d", v))
+		}
+	}
+	return names
+}
@@ -0,0 +1,35 @@
+package ghupdate
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detectARMVersion returns the ARM architecture version of the running CPU, judging by the
+// machine name reported by uname, e.g. "armv6l" on a Raspberry Pi Zero. ARMv8 CPUs running 32-bit
+// code ("armv8l") report 7, the newest version of 32-bit Go binaries.
+// It returns false if the version cannot be determined.
+func detectARMVersion() (int, bool) {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return 0, false
+	}
+	var machine strings.Builder
+	for _, c := range uts.Machine {
+		if c == 0 {
+			break
+		}
+		machine.WriteByte(byte(c))
+	}
+
+	rest, ok := strings.CutPrefix(machine.String(), "armv")
+	if !ok || rest == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(rest[:1])
+	if err != nil {
+		return 0, false
+	}
+	return min(v, defaultARMVersion), true
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package ghupdate
+
+// detectARMVersion reports that the ARM version of the CPU cannot be determined on this platform.
+func detectARMVersion() (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,255 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// apiAssetPathSegment identifies release asset URLs served by the GitHub REST API rather than the browser endpoint.
+const apiAssetPathSegment = "/releases/assets/"
+
+// errIncompleteDownload is returned when a download ends before the expected number of bytes arrived.
+var errIncompleteDownload = errors.New("download is incomplete")
+
+// statusError reports a request that failed with a non-OK HTTP status.
+type statusError struct {
+	URL        string
+	StatusCode int
+	// API is set for GitHub API requests, as opposed to asset downloads.
+	API bool
+}
+
+func (e *statusError) Error() string {
+	if e.API {
+		return fmt.Sprintf("GitHub API returned status %d for %s", e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("download from %q failed with status %d", e.URL, e.StatusCode)
+}
+
+// apiAssetURL returns the REST API endpoint of a release asset, which, unlike browser_download_url,
+// accepts token authentication and therefore works for private repositories.
+// It returns an empty string if the asset carries neither an API URL nor an ID.
+func apiAssetURL(config UpdateConfig, asset *GitHubAsset) string {
+	if asset.URL != "" {
+		return asset.URL
+	}
+	if asset.ID != 0 {
+		return fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/assets/%d", config.GitHubOwner, config.GitHubRepo, asset.ID)
+	}
+	return ""
+}
+
+// assetDownloadURLs returns the URLs to try, in order, when downloading a release asset.
+// When a token is configured, the API endpoint is tried first since browser downloads of private
+// assets do not accept tokens; otherwise the browser URL is tried first and the API endpoint is
+// used as a fallback if it returns 404.
+func assetDownloadURLs(config UpdateConfig, asset *GitHubAsset) []string {
+	apiURL := apiAssetURL(config, asset)
+	if apiURL == "" || apiURL == asset.BrowserDownloadURL {
+		return []string{asset.BrowserDownloadURL}
+	}
+	if asset.BrowserDownloadURL == "" {
+		return []string{apiURL}
+	}
+	if token, err := resolveToken(config); err == nil && token.Value != "" {
+		return []string{apiURL}
+	}
+	return []string{asset.BrowserDownloadURL, apiURL}
+}
+
+// downloadReleaseAsset downloads a release asset to destPath, falling back from the browser URL to
+// the API endpoint when the former is not found (see assetDownloadURLs).
+// Transient failures are retried according to the retry policy, resuming where the previous attempt
+// stopped if resumable is set (see downloadAsset).
+//
+// It returns the SHA-256 computed while downloading, if any (see downloadAsset).
+func downloadReleaseAsset(config UpdateConfig, asset *GitHubAsset, destPath string, resumable bool) (string, error) {
+	var sum string
+	var err error
+	for _, url := range assetDownloadURLs(config, asset) {
+		err = withRetry(config, func() error {
+			var downloadErr error
+			sum, downloadErr = downloadAssetAs(config, url, destPath, asset.Size, resumable)
+			return downloadErr
+		})
+		var status *statusError
+		if !errors.As(err, &status) || status.StatusCode != http.StatusNotFound {
+			return sum, err
+		}
+	}
+	return "", err
+}
+
+// isAPIAssetURL reports whether url points to the REST API asset endpoint.
+func isAPIAssetURL(url string) bool {
+	return strings.HasPrefix(url, "https://api.github.com/") && strings.Contains(url, apiAssetPathSegment)
+}
+
+// setAssetAccept asks the REST API asset endpoint for the raw asset content rather than its
+// JSON description. Other URLs are left untouched.
+func setAssetAccept(req *http.Request) {
+	if isAPIAssetURL(req.URL.String()) {
+		req.Header.Set("Accept", "application/octet-stream")
+	}
+}
+
+// ErrNoMatchingAsset is returned when a release has no asset matching the asset pattern for the target platform.
+var ErrNoMatchingAsset = errors.New("no asset found matching pattern")
+
+// ErrAmbiguousAsset is returned when several release assets match the asset pattern and they cannot
+// be told apart by their digests or UpdateConfig.AssetPriority.
+var ErrAmbiguousAsset = errors.New("multiple assets match the asset pattern")
+
+// matchAssets returns the assets whose name matches the expanded asset pattern name.
+// The name may contain path.Match wildcards (*, ?, [...]); otherwise it must match exactly.
+func matchAssets(assets []GitHubAsset, name string) []GitHubAsset {
+	var matches []GitHubAsset
+	for _, asset := range assets {
+		if asset.Name == name {
+			return []GitHubAsset{asset}
+		}
+		if ok, err := path.Match(name, asset.Name); err == nil && ok {
+			matches = append(matches, asset)
+		}
+	}
+	return matches
+}
+
+// filterContentTypes splits candidates into those whose content type is allowed by
+// config.AllowedContentTypes and the descriptions of those rejected. Media type parameters
+// (e.g., "; charset=binary") and case are ignored, and assets without a content type are allowed.
+func filterContentTypes(config UpdateConfig, candidates []GitHubAsset) ([]GitHubAsset, []string) {
+	if len(config.AllowedContentTypes) == 0 {
+		return candidates, nil
+	}
+	var allowed []GitHubAsset
+	var rejected []string
+	for _, asset := range candidates {
+		mediaType, _, _ := strings.Cut(asset.ContentType, ";")
+		mediaType = strings.TrimSpace(mediaType)
+		if mediaType == "" || slices.ContainsFunc(config.AllowedContentTypes, func(t string) bool {
+			return strings.EqualFold(t, mediaType)
+		}) {
+			allowed = append(allowed, asset)
+		} else {
+			rejected = append(rejected, fmt.Sprintf("%s (%s)", asset.Name, mediaType))
+		}
+	}
+	return allowed, rejected
+}
+
+// filterDecodable splits candidates into those that can be installed on this machine and the
+// descriptions of archives and compressed files that cannot, as the tool decompressing them is not
+// installed (see canDecompress).
+func filterDecodable(candidates []GitHubAsset) ([]GitHubAsset, []string) {
+	var decodable []GitHubAsset
+	var rejected []string
+	for _, asset := range candidates {
+		format := archiveFormat(asset.Name)
+		if canDecompress(format) {
+			decodable = append(decodable, asset)
+		} else {
+			rejected = append(rejected, fmt.Sprintf("%s (requires %s)", asset.Name, decompressionTools[strings.TrimPrefix(format, "tar.")]))
+		}
+	}
+	return decodable, rejected
+}
+
+// disambiguateAssets picks one asset out of several that match the asset pattern.
+// Candidates with identical content, as declared by their size and digest, are interchangeable, so
+// the first is used. With config.PreferSmallestAsset, the smallest installable candidate is used
+// (see smallestAsset). Otherwise the config.AssetPriority patterns are applied in order and the
+// first pattern that matches exactly one candidate decides.
+//
+// It returns an error wrapping ErrAmbiguousAsset that lists every candidate if no rule applies.
+func disambiguateAssets(config UpdateConfig, candidates []GitHubAsset) (*GitHubAsset, error) {
+	if len(candidates) == 1 || sameAssetContent(candidates) {
+		return &candidates[0], nil
+	}
+
+	if config.PreferSmallestAsset {
+		if asset := smallestAsset(candidates); asset != nil {
+			return asset, nil
+		}
+	}
+
+	for _, pattern := range config.AssetPriority {
+		var preferred []GitHubAsset
+		for _, asset := range candidates {
+			if ok, err := path.Match(pattern, asset.Name); err == nil && ok {
+				preferred = append(preferred, asset)
+			}
+		}
+		if len(preferred) == 1 {
+			return &preferred[0], nil
+		}
+	}
+
+	described := make([]string, len(candidates))
+	for i, asset := range candidates {
+		described[i] = fmt.Sprintf("%s (%d bytes", asset.Name, asset.Size)
+		if asset.Digest != "" {
+			described[i] += ", " + asset.Digest
+		}
+		described[i] += ")"
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAmbiguousAsset, strings.Join(described, ", "))
+}
+
+// nonExecutableSuffixes lists name suffixes of release assets that can never be installed as the
+// executable, such as signatures, checksums and OS packages, which a wildcard pattern may match
+// alongside the executable.
+var nonExecutableSuffixes = []string{
+	".sig", ".asc", ".minisig", ".pem", ".crt", ".sha256", ".sha512", ".sha256sum", ".md5", ".sbom",
+	".spdx", ".json", ".jsonl", ".txt", ".md", ".deb", ".rpm", ".apk", ".msi", ".dmg", ".pkg",
+}
+
+// isInstallableAsset reports whether an asset can be installed, judging by its name: an archive or
+// compressed file (see archiveFormat), or a plain executable.
+func isInstallableAsset(name string) bool {
+	if archiveFormat(name) != "" {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, suffix := range nonExecutableSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// smallestAsset returns the smallest installable candidate, preferring the earlier of equally
+// sized ones, so that a compressed asset is downloaded rather than the plain executable and the
+// best compressed one among several formats. It returns nil if no candidate is installable or a
+// size is unknown.
+func smallestAsset(candidates []GitHubAsset) *GitHubAsset {
+	var smallest *GitHubAsset
+	for i := range candidates {
+		asset := &candidates[i]
+		if !isInstallableAsset(asset.Name) {
+			continue
+		}
+		if asset.Size <= 0 {
+			return nil
+		}
+		if smallest == nil || asset.Size < smallest.Size {
+			smallest = asset
+		}
+	}
+	return smallest
+}
+
+// sameAssetContent reports whether all assets have the same size and the same non-empty digest.
+func sameAssetContent(assets []GitHubAsset) bool {
+	for _, asset := range assets {
+		if asset.Digest == "" || asset.Digest != assets[0].Digest || asset.Size != assets[0].Size {
+			return false
+		}
+	}
+	return true
+}
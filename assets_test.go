@@ -0,0 +1,33 @@
+package ghupdate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindMatchingAssetSkipsUndecodableFormats(t *testing.T) {
+	// Without the xz and zstd tools in PATH, only the gzip archive can be installed
+	t.Setenv("PATH", t.TempDir())
+
+	release := &GitHubRelease{
+		TagName: "v1.2.3",
+		Assets: []GitHubAsset{
+			{Name: "myapp-linux-amd64.tar.zst", BrowserDownloadURL: "https://example.com/zst", Size: 80},
+			{Name: "myapp-linux-amd64.tar.xz", BrowserDownloadURL: "https://example.com/xz", Size: 90},
+			{Name: "myapp-linux-amd64.tar.gz", BrowserDownloadURL: "https://example.com/gz", Size: 100},
+		},
+	}
+	config := UpdateConfig{AssetPattern: "myapp-{os}-{arch}*", PreferSmallestAsset: true}
+	asset, err := findMatchingAsset(config, release, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("findMatchingAsset: %v", err)
+	}
+	if asset.Name != "myapp-linux-amd64.tar.gz" {
+		t.Errorf("findMatchingAsset chose %s, want the .tar.gz archive", asset.Name)
+	}
+
+	release.Assets = release.Assets[:2]
+	if _, err := findMatchingAsset(config, release, "linux", "amd64"); !errors.Is(err, ErrNoMatchingAsset) {
+		t.Errorf("findMatchingAsset with only xz and zstd archives = %v, want ErrNoMatchingAsset", err)
+	}
+}
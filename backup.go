@@ -0,0 +1,252 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupDirName is the directory in DataDir where replaced executables are kept.
+const backupDirName = "backups"
+
+// ErrNoBackup is returned by Rollback and RestoreBackup when no matching previous executable has been kept.
+var ErrNoBackup = errors.New("no previous version to roll back to")
+
+// BackupPolicy controls how many replaced executables are kept in DataDir for Rollback and
+// RestoreBackup. Zero fields take their value from DefaultBackupPolicy.
+type BackupPolicy struct {
+	// Keep is the number of replaced executables kept, the oldest being removed first. Set it to a
+	// negative value to disable backups.
+	Keep int
+	// MaxBytes is the total size the kept executables may take up, the oldest being removed first
+	// once it is exceeded. An executable larger than MaxBytes is not kept. Zero means no limit.
+	MaxBytes int64
+}
+
+// DefaultBackupPolicy is the backup policy used when UpdateConfig.Backups is nil.
+var DefaultBackupPolicy = BackupPolicy{
+	Keep: 1,
+}
+
+// String formats the policy in the "keep:maxbytes" form understood by parseBackupPolicy.
+func (p BackupPolicy) String() string {
+	return strconv.Itoa(p.Keep) + ":" + strconv.FormatInt(p.MaxBytes, 10)
+}
+
+// parseBackupPolicy parses a policy in the "keep:maxbytes" form produced by BackupPolicy.String.
+func parseBackupPolicy(s string) (*BackupPolicy, error) {
+	keep, maxBytes, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid backup policy %q", s)
+	}
+	k, err := strconv.Atoi(keep)
+	if err != nil {
+		return nil, fmt.Errorf("invalid count in backup policy %q: %w", s, err)
+	}
+	m, err := strconv.ParseInt(maxBytes, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size in backup policy %q: %w", s, err)
+	}
+	return &BackupPolicy{Keep: k, MaxBytes: m}, nil
+}
+
+// backupPolicy returns the effective backup policy for policy, which may be nil.
+func backupPolicy(policy *BackupPolicy) BackupPolicy {
+	if policy == nil {
+		return DefaultBackupPolicy
+	}
+	p := *policy
+	if p.Keep == 0 {
+		p.Keep = DefaultBackupPolicy.Keep
+	}
+	return p
+}
+
+// Backup describes a copy of an executable kept before it was replaced by an update.
+type Backup struct {
+	// Version is the version of the replaced executable, if known.
+	Version string `json:"version"`
+	// Size is the size of the copy in bytes.
+	Size int64 `json:"size"`
+	// CreatedAt is the time the copy was made.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// backupDir returns the directory in dataDir where replaced executables are kept.
+func backupDir(dataDir string) string {
+	return filepath.Join(dataDir, backupDirName)
+}
+
+// backupPath returns the path at which the executable of version is kept in dataDir, with
+// characters that are not safe in file names replaced.
+func backupPath(dataDir, version string) string {
+	if version == "" {
+		version = "previous"
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '+':
+			return r
+		}
+		return '_'
+	}, version)
+	return filepath.Join(backupDir(dataDir), name+getExecutableExtension())
+}
+
+// backupExecutable copies the executable at path, which runs version, to the backup directory in
+// config.DataDir before it is replaced by an update, so that Rollback can restore it. The oldest
+// copies are then removed as required by config.Backups (see BackupPolicy).
+//
+// It returns an error if the copy cannot be made or recorded.
+func backupExecutable(config UpdateConfig, path, version string) error {
+	dataDir := config.DataDir
+	p := backupPolicy(config.Backups)
+	if p.Keep < 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to back up %q: %w", path, err)
+	}
+	if p.MaxBytes > 0 && info.Size() > p.MaxBytes {
+		return nil
+	}
+
+	if err := os.MkdirAll(backupDir(dataDir), dirMode(config)); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	dst := backupPath(dataDir, version)
+	tmp := dst + ".tmp"
+	if err := copyFile(path, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up %q: %w", path, err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up %q: %w", path, err)
+	}
+
+	record := Backup{
+		Version:   version,
+		Size:      info.Size(),
+		CreatedAt: time.Now().UTC(),
+	}
+	var pruned []Backup
+	err = updateState(config, func(state *updaterState) {
+		backups := slices.DeleteFunc(state.Backups, func(b Backup) bool { return b.Version == version })
+		backups = append(backups, record)
+		var total int64
+		for _, b := range backups {
+			total += b.Size
+		}
+		for len(backups) > p.Keep || (p.MaxBytes > 0 && total > p.MaxBytes) {
+			pruned = append(pruned, backups[0])
+			total -= backups[0].Size
+			backups = backups[1:]
+		}
+		state.Backups = backups
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range pruned {
+		os.Remove(backupPath(dataDir, b.Version))
+	}
+	return nil
+}
+
+// ListBackups returns the replaced executables kept in dataDir, oldest first, for offering a
+// choice of versions to roll back to (see RestoreBackup).
+func ListBackups(dataDir string) ([]Backup, error) {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return state.Backups, nil
+}
+
+// Rollback restores the executable that the most recent update replaced, for recovering from a bad
+// release. It is RestoreBackup with the newest kept version.
+//
+// It returns the restored version, or an error wrapping ErrNoBackup if no previous executable has
+// been kept, or an error if it cannot be restored.
+func Rollback(config UpdateConfig) (string, error) {
+	if config.DataDir == "" {
+		return "", fmt.Errorf("invalid config: DataDir is required")
+	}
+	backups, err := ListBackups(config.DataDir)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", ErrNoBackup
+	}
+	version := backups[len(backups)-1].Version
+	if err := RestoreBackup(config, version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// RestoreBackup restores the replaced executable of version kept in DataDir (see ListBackups and
+// BackupPolicy). The copies are made by HandleUpdateMode and the in-process installation paths just
+// before the executable is overwritten. ExecutablePath is replaced from the running process, so the
+// restored version runs from the next start; CurrentVersion is recorded as replaced in the update
+// history, and the copy is removed. Termination signals received meanwhile are deferred until the
+// executable has been restored, and then end the process. Restoring does not prevent newer
+// releases from being installed again: call Pin with version to stay on it.
+//
+// It returns an error wrapping ErrNoBackup if version has not been kept, or an error if it cannot
+// be restored.
+func RestoreBackup(config UpdateConfig, version string) error {
+	if config.DataDir == "" || config.ExecutablePath == "" {
+		return fmt.Errorf("invalid config: DataDir and ExecutablePath are required")
+	}
+	backups, err := ListBackups(config.DataDir)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(backups, func(b Backup) bool { return b.Version == version }) {
+		return fmt.Errorf("%w: %s has not been kept", ErrNoBackup, version)
+	}
+	path := backupPath(config.DataDir, version)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%w: %v", ErrNoBackup, err)
+	}
+
+	// Defer termination signals for the duration of the replacement window
+	defer exitOnDeferredSignal(deferSignals(nil))
+
+	err = retryWhileBusy(config.ReplaceRetryTimeout, func() error {
+		return replaceRunningExecutable(path, config.ExecutablePath)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", version, err)
+	}
+	if err := applyOwnership(config, config.ExecutablePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// The backup has been installed and is no longer needed
+	os.Remove(path)
+	err = updateState(config, func(state *updaterState) {
+		state.Backups = slices.DeleteFunc(state.Backups, func(b Backup) bool { return b.Version == version })
+		state.History = append(state.History, UpdateRecord{
+			FromVersion: config.CurrentVersion,
+			ToVersion:   version,
+			CompletedAt: time.Now().UTC(),
+		})
+		if len(state.History) > maxUpdateHistory {
+			state.History = state.History[len(state.History)-maxUpdateHistory:]
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CheckAndPrepareUpdate when a release failed verification or
+// installation too often and is not retried until its circuit breaker cooldown has elapsed.
+var ErrCircuitOpen = errors.New("release is temporarily skipped after repeated failures")
+
+// CircuitBreakerPolicy controls how releases that repeatedly fail verification or installation are
+// skipped. Zero fields take their value from DefaultCircuitBreakerPolicy.
+type CircuitBreakerPolicy struct {
+	// MaxFailures is the number of failures of a release after which it is skipped. Set it to a
+	// negative value to disable the circuit breaker.
+	MaxFailures int
+	// Cooldown is how long a release is skipped once the circuit is open. It doubles with every
+	// further failure after a cooldown, up to eight times the initial value.
+	Cooldown time.Duration
+}
+
+// DefaultCircuitBreakerPolicy is the circuit breaker policy used when UpdateConfig.CircuitBreaker is nil.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	MaxFailures: 3,
+	Cooldown:    24 * time.Hour,
+}
+
+// VersionFailures describes the failures recorded for a release.
+type VersionFailures struct {
+	// Version is the release version.
+	Version string `json:"version"`
+	// Count is the number of failures since the release last succeeded.
+	Count int `json:"count"`
+	// LastError is the message of the most recent failure.
+	LastError string `json:"last_error"`
+	// LastFailure is the time of the most recent failure.
+	LastFailure time.Time `json:"last_failure"`
+	// OpenUntil is the time until which the release is skipped, or zero if the circuit is closed.
+	OpenUntil time.Time `json:"open_until,omitzero"`
+}
+
+// Open reports whether the release is currently skipped.
+func (f VersionFailures) Open() bool {
+	return time.Now().Before(f.OpenUntil)
+}
+
+// verificationError marks a failure to verify a downloaded asset, as opposed to a failure to download
+// it, so that only the former counts towards the circuit breaker.
+type verificationError struct {
+	err error
+}
+
+func (e *verificationError) Error() string { return e.err.Error() }
+func (e *verificationError) Unwrap() error { return e.err }
+
+// circuitBreakerPolicy returns the effective circuit breaker policy for config.
+func circuitBreakerPolicy(config UpdateConfig) CircuitBreakerPolicy {
+	if config.CircuitBreaker == nil {
+		return DefaultCircuitBreakerPolicy
+	}
+	policy := *config.CircuitBreaker
+	if policy.MaxFailures == 0 {
+		policy.MaxFailures = DefaultCircuitBreakerPolicy.MaxFailures
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultCircuitBreakerPolicy.Cooldown
+	}
+	return policy
+}
+
+// FailedVersions returns the releases with recorded verification or installation failures, for
+// diagnostics, including whether their circuit is open.
+func FailedVersions(dataDir string) ([]VersionFailures, error) {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return state.Failures, nil
+}
+
+// ResetFailures clears the failures recorded for version, closing its circuit, e.g. after the
+// underlying problem has been fixed. An empty version clears all failures.
+func ResetFailures(dataDir, version string) error {
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.Failures = slices.DeleteFunc(state.Failures, func(f VersionFailures) bool {
+			return version == "" || f.Version == version
+		})
+	})
+}
+
+// checkCircuit returns an error wrapping ErrCircuitOpen if version is currently skipped.
+// An open-until time further in the future than the longest cooldown is not trusted, since it can
+// only result from a clock change, and is ignored.
+func checkCircuit(config UpdateConfig, version string) error {
+	policy := circuitBreakerPolicy(config)
+	if policy.MaxFailures < 0 {
+		return nil
+	}
+	state, err := loadState(config.DataDir)
+	if err != nil {
+		return nil
+	}
+	for _, f := range state.Failures {
+		if f.Version != version || !f.Open() {
+			continue
+		}
+		if time.Until(f.OpenUntil) > 8*policy.Cooldown {
+			return nil
+		}
+		return fmt.Errorf("%w: %s failed %d times (last: %s); retrying after %s", ErrCircuitOpen, version, f.Count, f.LastError, f.OpenUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// recordFailure counts a verification or installation failure of version, opening its circuit once
+// the policy's failure threshold is reached. Failures are recorded on a best-effort basis.
+func recordFailure(config UpdateConfig, version string, failure error) {
+	policy := circuitBreakerPolicy(config)
+	if policy.MaxFailures < 0 || config.DataDir == "" || version == "" {
+		return
+	}
+	updateState(config, func(state *updaterState) {
+		i := slices.IndexFunc(state.Failures, func(f VersionFailures) bool { return f.Version == version })
+		if i < 0 {
+			state.Failures = append(state.Failures, VersionFailures{Version: version})
+			i = len(state.Failures) - 1
+		}
+		f := &state.Failures[i]
+		f.Count++
+		f.LastError = failure.Error()
+		f.LastFailure = time.Now().UTC()
+		if f.Count >= policy.MaxFailures {
+			f.OpenUntil = f.LastFailure.Add(policy.Cooldown << min(f.Count-policy.MaxFailures, 3))
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package ghupdate
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// bsdiffMagic starts patches in the classic bsdiff 4.x format.
+const bsdiffMagic = "BSDIFF40"
+
+// errCorruptPatch is returned for malformed bsdiff patches.
+var errCorruptPatch = errors.New("corrupt bsdiff patch")
+
+// bspatch applies a bsdiff 4.x patch of patchSize bytes to old, of oldSize bytes, writing the new
+// file to w. The patch holds a header followed by three bzip2 streams: control triples, bytes added
+// to the old file and bytes inserted verbatim. Both files are read on demand, so large executables
+// are never held in memory.
+//
+// It returns an error if the patch is malformed or reading and writing fail.
+func bspatch(old io.ReaderAt, oldSize int64, patch io.ReaderAt, patchSize int64, w io.Writer) error {
+	header := make([]byte, 32)
+	if _, err := patch.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("failed to read patch header: %w", err)
+	}
+	if !bytes.Equal(header[:8], []byte(bsdiffMagic)) {
+		return fmt.Errorf("%w: not a %s patch", errCorruptPatch, bsdiffMagic)
+	}
+	ctrlLen, diffLen, newSize := bsdiffOffset(header[8:16]), bsdiffOffset(header[16:24]), bsdiffOffset(header[24:32])
+	// Compare against the remaining size rather than summing, so that huge lengths cannot overflow
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || patchSize < 32 || ctrlLen > patchSize-32 || diffLen > patchSize-32-ctrlLen {
+		return fmt.Errorf("%w: invalid header", errCorruptPatch)
+	}
+
+	ctrl := bzip2.NewReader(io.NewSectionReader(patch, 32, ctrlLen))
+	diff := bufio.NewReader(bzip2.NewReader(io.NewSectionReader(patch, 32+ctrlLen, diffLen)))
+	extra := bufio.NewReader(bzip2.NewReader(io.NewSectionReader(patch, 32+ctrlLen+diffLen, patchSize-32-ctrlLen-diffLen)))
+
+	buf := make([]byte, 64*1024)
+	oldBuf := make([]byte, len(buf))
+	triple := make([]byte, 24)
+	var oldPos, newPos int64
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, triple); err != nil {
+			return fmt.Errorf("%w: truncated control block: %v", errCorruptPatch, err)
+		}
+		add, insert, seek := bsdiffOffset(triple[0:8]), bsdiffOffset(triple[8:16]), bsdiffOffset(triple[16:24])
+		addEnd, addOK := addOffsets(oldPos, add)
+		_, seekOK := addOffsets(addEnd, seek)
+		if add < 0 || insert < 0 || add > newSize-newPos || insert > newSize-newPos-add || !addOK || !seekOK {
+			return fmt.Errorf("%w: invalid control triple", errCorruptPatch)
+		}
+
+		// Add the diff bytes to the old bytes at oldPos; bytes outside the old file count as zero
+		for remaining := add; remaining > 0; {
+			n := min(remaining, int64(len(buf)))
+			if _, err := io.ReadFull(diff, buf[:n]); err != nil {
+				return fmt.Errorf("%w: truncated diff block: %v", errCorruptPatch, err)
+			}
+			start, end := max(oldPos, 0), min(oldPos+n, oldSize)
+			if start < end {
+				if _, err := old.ReadAt(oldBuf[:end-start], start); err != nil {
+					return fmt.Errorf("failed to read current executable: %w", err)
+				}
+				for i := start; i < end; i++ {
+					buf[i-oldPos] += oldBuf[i-start]
+				}
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			oldPos += n
+			remaining -= n
+		}
+
+		if _, err := io.CopyN(w, extra, insert); err != nil {
+			return fmt.Errorf("%w: truncated extra block: %v", errCorruptPatch, err)
+		}
+		newPos += add + insert
+		oldPos += seek
+	}
+	return nil
+}
+
+// bsdiffOffset decodes a bsdiff offset: a little-endian 64-bit magnitude with the sign in the top bit.
+func bsdiffOffset(b []byte) int64 {
+	v := int64(binary.LittleEndian.Uint64(b) &^ (1 << 63))
+	if b[7]&0x80 != 0 {
+		return -v
+	}
+	return v
+}
+
+// addOffsets returns a+b, or false if the sum overflows.
+func addOffsets(a, b int64) (int64, bool) {
+	sum := a + b
+	return sum, (b >= 0) == (sum >= a)
+}
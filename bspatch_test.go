@@ -0,0 +1,96 @@
+package ghupdate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The patches in testdata/bspatch turn old into new: the first control triple adds 44 diff bytes to
+// the old file (changing "fox" to "cat"), inserts 11 extra bytes and seeks back to the start of the
+// old file, and the second copies its first 9 bytes unchanged. The other patches are malformed.
+
+func readBspatchFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "bspatch", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func applyBspatch(old, patch []byte) ([]byte, error) {
+	var out bytes.Buffer
+	err := bspatch(bytes.NewReader(old), int64(len(old)), bytes.NewReader(patch), int64(len(patch)), &out)
+	return out.Bytes(), err
+}
+
+func TestBspatch(t *testing.T) {
+	old, want := readBspatchFile(t, "old"), readBspatchFile(t, "new")
+	got, err := applyBspatch(old, readBspatchFile(t, "good.patch"))
+	if err != nil {
+		t.Fatalf("bspatch: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("bspatch = %q, want %q", got, want)
+	}
+}
+
+func TestBspatchMalformedHeader(t *testing.T) {
+	good := readBspatchFile(t, "good.patch")
+	withHeader := func(offset int, value uint64) []byte {
+		patch := bytes.Clone(good)
+		binary.LittleEndian.PutUint64(patch[offset:], value)
+		return patch
+	}
+
+	tests := []struct {
+		name  string
+		patch []byte
+	}{
+		{"empty", nil},
+		{"truncated header", good[:20]},
+		{"bad magic", append([]byte("BSDIFF41"), good[8:]...)},
+		{"negative control length", withHeader(8, 1<<63|10)},
+		{"negative diff length", withHeader(16, 1<<63|10)},
+		{"negative new size", withHeader(24, 1<<63|10)},
+		{"control length past the end", withHeader(8, uint64(len(good)))},
+		{"diff length past the end", withHeader(16, uint64(len(good)))},
+		// 32+ctrlLen+diffLen overflows to a negative length
+		{"overflowing lengths", withHeader(16, math.MaxInt64)},
+		{"corrupt control block", withHeader(8, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := applyBspatch(readBspatchFile(t, "old"), tt.patch); err == nil {
+				t.Error("bspatch succeeded, want an error")
+			}
+		})
+	}
+
+	patch := withHeader(8, math.MaxInt64)
+	binary.LittleEndian.PutUint64(patch[16:], math.MaxInt64)
+	if _, err := applyBspatch(nil, patch); !errors.Is(err, errCorruptPatch) {
+		t.Errorf("bspatch with control and diff lengths summing past MaxInt64 = %v, want errCorruptPatch", err)
+	}
+}
+
+func TestBspatchInvalidControlTriple(t *testing.T) {
+	for _, name := range []string{
+		"negative-add.patch",
+		"beyond-new-size.patch",
+		"insert-overflow.patch",
+		"seek-overflow.patch",
+		"truncated-control.patch",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := applyBspatch(readBspatchFile(t, "old"), readBspatchFile(t, name)); !errors.Is(err, errCorruptPatch) {
+				t.Errorf("bspatch = %v, want errCorruptPatch", err)
+			}
+		})
+	}
+}
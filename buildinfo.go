@@ -0,0 +1,95 @@
+package ghupdate
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Build metadata embedded at link time, e.g.:
+//
+//	go build -ldflags "-X github.com/asaidimu/ghupdate.AppVersion=$(git describe --tags) \
+//	  -X github.com/asaidimu/ghupdate.AppCommit=$(git rev-parse HEAD) \
+//	  -X github.com/asaidimu/ghupdate.AppBuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values left empty are filled from the module and VCS information recorded by the Go toolchain
+// (see ReadVersionInfo).
+var (
+	// AppVersion is the release version of the application, e.g. "v1.2.3".
+	AppVersion string
+	// AppCommit is the VCS revision the application was built from.
+	AppCommit string
+	// AppBuildDate is the time the application was built.
+	AppBuildDate string
+)
+
+// VersionInfo describes the build of the running application.
+type VersionInfo struct {
+	// Version is the release version, or "dev" if unknown.
+	Version string
+	// Commit is the VCS revision, if known.
+	Commit string
+	// BuildDate is the build or commit time, if known.
+	BuildDate string
+	// Modified reports whether the build was made from a working tree with uncommitted changes.
+	Modified bool
+}
+
+// ReadVersionInfo returns the build metadata of the running application. Values set with -ldflags
+// on AppVersion, AppCommit and AppBuildDate take precedence; otherwise the main module version (set by
+// `go install module@version`) and the vcs.revision and vcs.time build settings are used.
+// The version defaults to "dev" when nothing is available.
+func ReadVersionInfo() VersionInfo {
+	info := VersionInfo{Version: AppVersion, Commit: AppCommit, BuildDate: AppBuildDate}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// Apply sets config.CurrentVersion from the version information.
+func (v VersionInfo) Apply(config *UpdateConfig) {
+	config.CurrentVersion = v.Version
+}
+
+// String formats the version information for display, e.g. "v1.2.3 (abc1234, 2024-05-01T10:00:00Z)".
+func (v VersionInfo) String() string {
+	var details []string
+	if v.Commit != "" {
+		commit := v.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		if v.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, commit)
+	}
+	if v.BuildDate != "" {
+		details = append(details, v.BuildDate)
+	}
+	if len(details) == 0 {
+		return v.Version
+	}
+	return v.Version + " (" + strings.Join(details, ", ") + ")"
+}
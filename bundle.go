@@ -0,0 +1,191 @@
+package ghupdate
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// bundleManifestName is the tar entry holding the bundle's manifest.
+	bundleManifestName = "manifest.json"
+	// bundleSignatureName is the tar entry holding the manifest signature.
+	bundleSignatureName = "manifest.json.sig"
+)
+
+// CreateBundle writes a signed, self-contained update bundle for manual distribution (e.g., through
+// ticketing systems or email in locked-down organizations).
+//
+// A bundle is a tar archive holding a manifest for the given version, its Ed25519 signature and the
+// executable itself, in that order. Consumers install it with PrepareBundle or ApplyBundle.
+//
+// It returns an error if the binary cannot be read, the key is invalid, or writing to w fails.
+func CreateBundle(w io.Writer, binaryPath, version string, key ed25519.PrivateKey) error {
+	manifest, err := GenerateManifest(version, binaryPath)
+	if err != nil {
+		return err
+	}
+	data, sig, err := SignManifest(manifest, key)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{{bundleManifestName, data}, {bundleSignatureName, sig}} {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data))}); err != nil {
+			return fmt.Errorf("failed to write bundle entry %s: %w", entry.name, err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write bundle entry %s: %w", entry.name, err)
+		}
+	}
+
+	binary, err := os.Open(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", binaryPath, err)
+	}
+	defer binary.Close()
+
+	asset := manifest.Assets[0]
+	if err := tw.WriteHeader(&tar.Header{Name: asset.Name, Mode: 0755, Size: asset.Size}); err != nil {
+		return fmt.Errorf("failed to write bundle entry %s: %w", asset.Name, err)
+	}
+	if _, err := io.Copy(tw, binary); err != nil {
+		return fmt.Errorf("failed to write bundle entry %s: %w", asset.Name, err)
+	}
+
+	return tw.Close()
+}
+
+// PrepareBundle verifies an update bundle created by CreateBundle against config.ManifestPublicKey
+// and stages the contained executable exactly like CheckAndPrepareUpdate stages a download,
+// so it can be installed with ApplyUpdate. No network access is needed.
+//
+// It returns nil if the bundle's version is not newer than CurrentVersion, or an error if the bundle
+// is malformed, its signature or hashes do not verify, or staging fails.
+func PrepareBundle(config UpdateConfig, bundlePath string) (*UpdateInfo, error) {
+	if len(config.ManifestPublicKey) == 0 {
+		return nil, fmt.Errorf("invalid config: ManifestPublicKey is required to verify bundles")
+	}
+	if config.CurrentVersion == "" || config.DataDir == "" || config.ExecutablePath == "" {
+		return nil, fmt.Errorf("invalid config: CurrentVersion, DataDir and ExecutablePath are required")
+	}
+
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle %q: %w", bundlePath, err)
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	data, err := readBundleEntry(tr, bundleManifestName)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := readBundleEntry(tr, bundleSignatureName)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := VerifyManifest(data, sig, config.ManifestPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("bundle verification failed: %w", err)
+	}
+	if len(manifest.Assets) != 1 {
+		return nil, fmt.Errorf("bundle manifest must list exactly one asset, got %d", len(manifest.Assets))
+	}
+
+	if !isNewerVersion(config, config.CurrentVersion, manifest.Version) {
+		return nil, nil // No update needed
+	}
+	if err := checkDowngrade(config, manifest.Version); err != nil {
+		return nil, err
+	}
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("bundle %q has no executable: %w", bundlePath, err)
+	}
+	asset := manifest.Assets[0]
+	if header.Name != asset.Name {
+		return nil, fmt.Errorf("bundle executable %q is not listed in its manifest", header.Name)
+	}
+
+	updatePath, err := chooseStagedUpdatePath(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(updatePath), dirMode(config)); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %q: %w", updatePath, err)
+	}
+	partialPath := partialDownloadPath(updatePath)
+	out, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination file %q: %w", partialPath, err)
+	}
+	_, err = io.Copy(out, io.LimitReader(tr, asset.Size+1))
+	out.Close()
+	if err != nil {
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("failed to extract bundle executable: %w", err)
+	}
+
+	if err := verifyManifestAsset(manifest, asset.Name, partialPath, ""); err != nil {
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("bundle verification failed: %w", err)
+	}
+	if err := os.Rename(partialPath, updatePath); err != nil {
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("failed to move verified executable into place: %w", err)
+	}
+
+	if err := finalizeStagedUpdate(config, updatePath, manifest.Version); err != nil {
+		return nil, err
+	}
+
+	return &UpdateInfo{
+		CurrentVersion: config.CurrentVersion,
+		LatestVersion:  manifest.Version,
+		AssetName:      asset.Name,
+	}, nil
+}
+
+// ApplyBundle prepares an update bundle with PrepareBundle and immediately applies it with ApplyUpdate.
+// Like ApplyUpdate, it does not return when the update is applied successfully.
+//
+// It returns nil if the bundle is not newer than the running version, or an error if preparing
+// or applying the bundle fails.
+func ApplyBundle(config UpdateConfig, bundlePath string) error {
+	info, err := PrepareBundle(config, bundlePath)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return nil // Already up to date
+	}
+	return ApplyUpdate(config)
+}
+
+// readBundleEntry reads the next tar entry, which must have the given name, into memory.
+func readBundleEntry(tr *tar.Reader, name string) ([]byte, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle entry %s: %w", name, err)
+	}
+	if header.Name != name {
+		return nil, fmt.Errorf("unexpected bundle entry %q, expected %s", header.Name, name)
+	}
+	if header.Size > maxManifestSize {
+		return nil, fmt.Errorf("bundle entry %s exceeds %d bytes", name, maxManifestSize)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle entry %s: %w", name, err)
+	}
+	return data, nil
+}
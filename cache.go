@@ -0,0 +1,179 @@
+package ghupdate
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sharedCacheDirMode is the mode of directories created in the shared cache: world-writable with the
+// sticky bit, like /tmp, so every user can add entries but only remove their own.
+const sharedCacheDirMode = os.ModeSticky | 0777
+
+// expectedSHA256 returns the hex-encoded SHA-256 an asset is known to have before it is downloaded,
+// from GitHub's digest or the signed manifest, or an empty string if neither provides one.
+func expectedSHA256(asset *GitHubAsset, manifest *Manifest) string {
+	if sum, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok {
+		return strings.ToLower(sum)
+	}
+	if manifest != nil {
+		if entry, ok := manifest.Asset(asset.Name); ok {
+			return strings.ToLower(entry.SHA256)
+		}
+	}
+	return ""
+}
+
+// localStoreDirName is the directory in DataDir holding the content-addressed store of verified downloads.
+const localStoreDirName = "cas"
+
+// localStoreKeep is the number of most recently used entries kept in the local store by garbage collection.
+const localStoreKeep = 3
+
+// storePath returns the hash-addressed location of an asset in the content-addressed store at root.
+func storePath(root, sum string) string {
+	return filepath.Join(root, "sha256", sum)
+}
+
+// localStoreRoot returns the root of the content-addressed store in DataDir, or an empty string if
+// it is disabled. It is disabled with EncryptStaging, since it would keep unencrypted copies at rest.
+func localStoreRoot(config UpdateConfig) string {
+	if config.DataDir == "" || config.EncryptStaging {
+		return ""
+	}
+	return filepath.Join(config.DataDir, localStoreDirName)
+}
+
+// lockSharedCacheEntry takes the lock of a shared cache entry so that only one user downloads it
+// at a time, waiting up to the download timeout for another holder to finish.
+// If the lock cannot be taken, the download proceeds unlocked.
+//
+// It returns a function that releases the lock.
+func lockSharedCacheEntry(config UpdateConfig, sum string) func() {
+	path := storePath(config.SharedCacheDir, sum)
+	if err := os.MkdirAll(filepath.Dir(path), sharedCacheDirMode); err != nil {
+		return func() {}
+	}
+	os.Chmod(config.SharedCacheDir, sharedCacheDirMode)
+	os.Chmod(filepath.Dir(path), sharedCacheDirMode)
+
+	deadline := time.Now().Add(downloadTimeout(config))
+	for {
+		release, err := acquireLock(path+".lock", fileMode(config)&^0111)
+		if err == nil {
+			return release
+		}
+		if !errors.Is(err, ErrLocked) || time.Now().After(deadline) {
+			return func() {}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// copyFromStore places the asset with the given SHA-256 from the store at root at destPath,
+// hard-linking it when possible and copying it otherwise. The placed file is hashed afterwards
+// and removed if it does not match, so a corrupted entry, or one rewritten by another user of a
+// shared store while it was being copied, is never used. Using an entry refreshes its
+// modification time for garbage collection.
+//
+// It returns true if the asset was found in the store and placed at destPath.
+func copyFromStore(config UpdateConfig, root, sum, destPath string, link bool) bool {
+	path := storePath(root, sum)
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), dirMode(config)); err != nil {
+		return false
+	}
+
+	os.Remove(destPath)
+	if !link || os.Link(path, destPath) != nil {
+		if err := copyFile(path, destPath); err != nil {
+			os.Remove(destPath)
+			return false
+		}
+	}
+	if actual, err := hashFile(destPath, sha256.New()); err != nil || actual != sum {
+		os.Remove(destPath)
+		return false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return true
+}
+
+// addToStore adds a verified asset to the store at root under its SHA-256, hard-linking it when
+// possible. Copies are written to a temporary file and renamed into place, so readers never see a
+// partial entry. Failures are ignored; the store is only an optimization.
+func addToStore(root, sum, srcPath string, link bool, dirMode os.FileMode) {
+	path := storePath(root, sum)
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return
+	}
+	if link && os.Link(srcPath, path) == nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+sum+".tmp-*")
+	if err != nil {
+		return
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+
+	if err := copyFile(srcPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+	}
+}
+
+// collectStoreGarbage removes all but the keep most recently used entries of the store at root.
+// The entry for current is always kept, even if a clock change made its modification time look old.
+func collectStoreGarbage(root string, keep int, current string) {
+	dir := filepath.Join(root, "sha256")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type entry struct {
+		name    string
+		modTime time.Time
+	}
+	var files []entry
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(e.Name(), ".") || e.Name() == current {
+			continue
+		}
+		files = append(files, entry{e.Name(), info.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b entry) int { return b.modTime.Compare(a.modTime) })
+
+	for _, f := range files[min(max(keep-1, 0), len(files)):] {
+		os.Remove(filepath.Join(dir, f.name))
+	}
+}
+
+// validateSharedCacheDir checks that a shared cache directory, if configured, is an absolute path.
+func validateSharedCacheDir(dir string) error {
+	if dir != "" && !filepath.IsAbs(dir) {
+		return fmt.Errorf("SharedCacheDir must be an absolute path, got %q", dir)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package ghupdate
+
+import (
+	"runtime"
+	"slices"
+)
+
+// Features describes what the ghupdate build linked into the running application supports. It is
+// meant for diagnostics commands and bug reports, so that the updater behavior of a shipped binary
+// can be told apart without inspecting its build.
+type Features struct {
+	// LibraryVersion is the version of ghupdate (see Version).
+	LibraryVersion string
+	// Platform is the operating system and architecture the binary was built for, e.g. "linux/amd64".
+	Platform string
+	// Providers lists the sources releases can be discovered from.
+	Providers []string
+	// Archives lists the asset formats that can be installed besides plain executables. xz and
+	// Zstandard formats are only listed if the xz or zstd tool decompressing them is installed.
+	Archives []string
+	// Signatures lists the supported ways of authenticating release assets.
+	Signatures []string
+	// Digests lists the supported asset digest algorithms.
+	Digests []string
+	// Delta lists the supported binary patch formats. It is empty when only full downloads are
+	// supported. zstd is only listed if the zstd tool is installed.
+	Delta []string
+	// DiskSpaceCheck reports whether free space is checked before downloading on this platform.
+	DiskSpaceCheck bool
+	// OSKeystore reports whether OSKeystoreKey can store staging keys on this platform.
+	OSKeystore bool
+	// Tools reports, for each external tool the updater can use, whether it is installed: xz and
+	// zstd for xz and Zstandard archives, and zstd for Zstandard patches.
+	Tools map[string]bool
+}
+
+// Capabilities returns the features supported by the ghupdate build linked into the running
+// application, on the machine it runs on: formats that need an external tool are only reported
+// if the tool is installed.
+func Capabilities() Features {
+	tools := map[string]bool{}
+	for _, tool := range decompressionTools {
+		tools[tool] = toolInstalled(tool)
+	}
+
+	archives := slices.DeleteFunc([]string{"bundle", "tar.gz", "tar.bz2", "tar.xz", "tar.zst", "zip", "gz", "bz2", "xz", "zst"}, func(format string) bool {
+		return format != "bundle" && !canDecompress(format)
+	})
+	delta := []string{"bsdiff"}
+	if tools["zstd"] {
+		delta = append(delta, "zstd")
+	}
+
+	return Features{
+		LibraryVersion: Version(),
+		Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		Providers:      []string{"github-api", "github-tags", "github-redirect", "github-atom"},
+		Archives:       archives,
+		Signatures:     []string{"ed25519-manifest", "tuf"},
+		Digests:        []string{"sha256", "sha512"},
+		Delta:          delta,
+		DiskSpaceCheck: diskSpaceSupported,
+		OSKeystore:     keystoreSupported,
+		Tools:          tools,
+	}
+}
@@ -0,0 +1,220 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ChannelStable is the default update channel. It follows GitHub's latest release, which never is a
+// draft or prerelease.
+const ChannelStable = "stable"
+
+// ErrChannelDowngrade is returned by SwitchChannel when the newest release of the target channel is
+// older than the running version and downgrading was not allowed.
+var ErrChannelDowngrade = errors.New("target channel has no release at or above the current version")
+
+// Channel returns the update channel the installation in dataDir follows: the one persisted by
+// SwitchChannel, or ChannelStable.
+func Channel(dataDir string) (string, error) {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return "", err
+	}
+	if state.Channel == "" {
+		return ChannelStable, nil
+	}
+	return state.Channel, nil
+}
+
+// SwitchChannel moves the installation to another update channel, such as "beta", and persists the
+// choice in DataDir so that subsequent checks follow it. A channel other than ChannelStable follows
+// the newest release that is either stable or a prerelease whose semantic version prerelease part
+// starts with the channel name (e.g., v1.4.0-beta.2 for "beta").
+//
+// The target channel must have a release at or above CurrentVersion. If it only has older releases
+// (e.g., when leaving beta for stable), allowDowngrade permits the next check to install the
+// channel's newest release, bypassing downgrade protection for that release only.
+//
+// It returns the newest release of the target channel, or an error wrapping ErrChannelDowngrade,
+// or another error if the releases cannot be fetched or the state cannot be saved.
+func SwitchChannel(config UpdateConfig, channel string, allowDowngrade bool) (*GitHubRelease, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if channel == "" {
+		channel = ChannelStable
+	}
+
+	config.Channel = channel
+	release, err := fetchChannelRelease(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest %s release: %w", channel, err)
+	}
+
+	downgrade := compareVersions(config, release.TagName, config.CurrentVersion) < 0
+	if downgrade && !allowDowngrade {
+		return nil, fmt.Errorf("%w: newest %s release is %s, running %s", ErrChannelDowngrade, channel, release.TagName, config.CurrentVersion)
+	}
+
+	err = updateState(config, func(state *updaterState) {
+		state.Channel = channel
+		if channel == ChannelStable {
+			state.Channel = ""
+		}
+		state.SwitchTarget = ""
+		if downgrade {
+			state.SwitchTarget = release.TagName
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// MigrateChannel moves the installation to another update channel, typically from a beta channel
+// back to ChannelStable, and checks for an update on it right away. If the newest release of the
+// target channel is at or above the running version, it is staged like any update. If it is older,
+// as when leaving a beta that is ahead of stable, downgrade selects what happens: if false, the
+// installation holds on the running version and follows the channel once it catches up; if true,
+// the channel's newest release is staged and reported with UpdateInfo.Downgrade set, for the
+// application to confirm with the user before calling ApplyUpdate.
+//
+// It returns the staged update, or nil if there is none or the installation holds, or an error if
+// the releases cannot be fetched, the state cannot be saved or the update cannot be prepared.
+func MigrateChannel(config UpdateConfig, channel string, downgrade bool) (*UpdateInfo, error) {
+	if channel == "" {
+		channel = ChannelStable
+	}
+
+	_, err := SwitchChannel(config, channel, downgrade)
+	if errors.Is(err, ErrChannelDowngrade) {
+		// Hold: follow the channel without moving below the running version
+		return nil, updateState(config, func(state *updaterState) {
+			state.Channel = channel
+			if channel == ChannelStable {
+				state.Channel = ""
+			}
+			state.SwitchTarget = ""
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.Channel = channel
+	return CheckAndPrepareUpdate(config)
+}
+
+// effectiveChannel returns config.Channel, or the channel persisted in DataDir if it is empty.
+func effectiveChannel(config UpdateConfig) string {
+	if config.Channel != "" {
+		return config.Channel
+	}
+	if config.DataDir != "" {
+		if channel, err := Channel(config.DataDir); err == nil {
+			return channel
+		}
+	}
+	return ChannelStable
+}
+
+// fetchChannelRelease fetches the newest release of the channel the installation follows.
+// With TagsFallback, repositories without releases are served from their tags (see fetchReleaseFromTags).
+// With AtomFeed, the release feed is polled first (see fetchChannelReleaseFromFeed), unless drafts
+// are included: the feed never lists them, so the releases API is queried instead.
+func fetchChannelRelease(config UpdateConfig) (*GitHubRelease, error) {
+	channel := effectiveChannel(config)
+	if config.AtomFeed && !config.IncludeDrafts {
+		return fetchChannelReleaseFromFeed(config, channel)
+	}
+	if config.RedirectMode {
+		if channel != ChannelStable {
+			return nil, fmt.Errorf("channel %q is not supported in redirect mode", channel)
+		}
+		if config.IncludePrereleases {
+			return nil, fmt.Errorf("prereleases are not supported in redirect mode")
+		}
+		if config.IncludeDrafts {
+			return nil, fmt.Errorf("draft releases are not supported in redirect mode")
+		}
+		return fetchLatestReleaseByRedirect(config)
+	}
+
+	release, err := fetchChannelReleaseFromAPI(config, channel)
+	if err != nil && config.TagsFallback && releasesUnavailable(err) {
+		return fetchReleaseFromTags(config, channel)
+	}
+	return release, err
+}
+
+// fetchChannelReleaseFromAPI fetches the newest release of channel from the releases API.
+// With IncludePrereleases, the newest release including all prereleases is returned instead.
+// With IncludeDrafts, draft releases of the channel are considered as well.
+func fetchChannelReleaseFromAPI(config UpdateConfig, channel string) (*GitHubRelease, error) {
+	if channel == ChannelStable && !config.IncludePrereleases && !config.IncludeDrafts {
+		return fetchLatestRelease(config)
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=100", config.GitHubOwner, config.GitHubRepo)
+	var releases []GitHubRelease
+	if err := fetchAPI(config, url, func(body []byte) error {
+		var err error
+		releases, err = decodeReleases(body, config.IncludeDrafts)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	var newest *GitHubRelease
+	for i := range releases {
+		if !config.IncludePrereleases && !inChannel(&releases[i], channel) {
+			continue
+		}
+		if newest == nil || compareVersions(config, releases[i].TagName, newest.TagName) > 0 {
+			newest = &releases[i]
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("%w in channel %q", errNoReleases, channel)
+	}
+	return newest, nil
+}
+
+// inChannel reports whether a release belongs to a non-stable channel: stable releases belong to
+// every channel, prereleases only to the channel named by their prerelease identifier.
+func inChannel(release *GitHubRelease, channel string) bool {
+	tag := release.TagName
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	pre := semver.Prerelease(tag)
+	if !release.Prerelease && pre == "" {
+		return true
+	}
+	return strings.HasPrefix(pre, "-"+channel)
+}
+
+// isSwitchTarget reports whether version is the release SwitchChannel allowed to be installed as a
+// downgrade. Once that release is running, the allowance is cleared and it becomes the highest
+// installed version for downgrade protection.
+func isSwitchTarget(config UpdateConfig, version string) bool {
+	if config.DataDir == "" {
+		return false
+	}
+	state, err := loadState(config.DataDir)
+	if err != nil || state.SwitchTarget == "" {
+		return false
+	}
+
+	if compareVersions(config, state.SwitchTarget, config.CurrentVersion) == 0 {
+		state.HighestVersion = config.CurrentVersion
+		state.SwitchTarget = ""
+		saveState(config, state)
+		return false
+	}
+	return state.SwitchTarget == version
+}
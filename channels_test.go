@@ -0,0 +1,53 @@
+package ghupdate
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc serves HTTP requests with a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFetchChannelReleaseAtomFeedWithDrafts(t *testing.T) {
+	var requested []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Host+req.URL.Path)
+		status, body := http.StatusNotFound, ""
+		if req.URL.Host == "api.github.com" && req.URL.Path == "/repos/owner/myapp/releases" {
+			status, body = http.StatusOK, `[{"tag_name":"v1.1.0","draft":true,"assets":[]},{"tag_name":"v1.0.0","assets":[]}]`
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+
+	config := UpdateConfig{
+		GitHubOwner:    "owner",
+		GitHubRepo:     "myapp",
+		CurrentVersion: "v1.0.0",
+		AtomFeed:       true,
+		IncludeDrafts:  true,
+		HTTPClient:     client,
+	}
+	release, err := fetchChannelRelease(config)
+	if err != nil {
+		t.Fatalf("fetchChannelRelease: %v", err)
+	}
+	if release.TagName != "v1.1.0" {
+		t.Errorf("fetchChannelRelease = %s, want the v1.1.0 draft", release.TagName)
+	}
+	for _, url := range requested {
+		if strings.HasSuffix(url, "/releases.atom") {
+			t.Errorf("fetchChannelRelease with IncludeDrafts polled the release feed, which never lists drafts")
+		}
+	}
+}
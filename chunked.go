@@ -0,0 +1,126 @@
+package ghupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultChunkedThreshold is the asset size from which parallel chunked downloads are used when
+// UpdateConfig.DownloadConnections is greater than one and ChunkedThreshold is zero.
+const defaultChunkedThreshold = 100 << 20
+
+// errRangeUnsupported is returned by downloadChunked when the server ignores Range requests.
+var errRangeUnsupported = errors.New("server does not support range requests")
+
+// useChunkedDownload reports whether an asset of the given size should be fetched over multiple connections.
+func useChunkedDownload(config UpdateConfig, size int64) bool {
+	threshold := config.ChunkedThreshold
+	if threshold <= 0 {
+		threshold = defaultChunkedThreshold
+	}
+	return config.DownloadConnections > 1 && size >= threshold
+}
+
+// downloadChunked downloads an asset of the given size to destPath by splitting it into
+// config.DownloadConnections byte ranges that are fetched concurrently and written in place.
+// The first failing range cancels the others and the partial file is removed; the assembled
+// file is verified by the caller like any other download.
+//
+// It returns an error wrapping errRangeUnsupported if the server answers a range request with
+// the full content, so the caller can fall back to a single stream.
+func downloadChunked(config UpdateConfig, url, destPath string, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), dirMode(config)); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", destPath, err)
+	}
+	out, err := openStagedFile(config, destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file %q: %w", destPath, err)
+	}
+	defer out.Close()
+	if err := out.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate %q: %w", destPath, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connections := int64(config.DownloadConnections)
+	chunkSize := (size + connections - 1) / connections
+
+	progress := newProgressTracker(config, size, 0)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for start := int64(0); start < size; start += chunkSize {
+		end := min(start+chunkSize, size) - 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := downloadRange(ctx, config, url, out, progress, start, end); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		out.Close()
+		os.Remove(destPath)
+		return firstErr
+	}
+	return nil
+}
+
+// downloadRange fetches bytes start through end (inclusive) of url and writes them to out at offset start,
+// counting the received bytes in progress.
+func downloadRange(ctx context.Context, config UpdateConfig, url string, out *os.File, progress *progressTracker, start, end int64) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request for %q: %w", url, err)
+	}
+	setUserAgent(config, req)
+	if err := setAuthHeader(config, req); err != nil {
+		return err
+	}
+	setAssetAccept(req)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	client := httpClient(config, downloadTimeout(config))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download range %d-%d from %q: %w", start, end, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("%w: %s", errRangeUnsupported, url)
+	}
+	if !resumedAt(resp, start) {
+		return &statusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	if config.StrictTransport {
+		if err := checkStrictResponse(resp, url, start, end, 0); err != nil {
+			return err
+		}
+	}
+
+	want := end - start + 1
+	written, err := io.Copy(io.NewOffsetWriter(out, start), io.TeeReader(io.LimitReader(resp.Body, want), progress))
+	if err != nil {
+		return fmt.Errorf("failed to write range %d-%d to %q: %w", start, end, out.Name(), err)
+	}
+	if written != want {
+		return fmt.Errorf("%w: range %d-%d from %q: expected %d bytes, got %d", errIncompleteDownload, start, end, url, want, written)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Package cli implements `update` subcommands for applications using ghupdate, so that
+// update preferences can be managed from the command line without each application writing
+// its own plumbing:
+//
+//	myapp update channel                  show the current channel
+//	myapp update channel beta             follow the beta channel
+//	myapp update channel stable --allow-downgrade
+//	myapp update pin v1.4.2               stay on (or move to) v1.4.2
+//	myapp update unpin                    follow the channel again
+//	myapp update backups                  list the versions kept for rolling back
+//	myapp update rollback [v1.4.1]        restore the previous (or a kept) version, after confirming
+//	myapp update rollback --yes           restore without asking
+//	myapp update reset [--dry-run]        remove all updater state (or list what would be removed)
+//
+// Preferences are persisted in the updater state in UpdateConfig.DataDir and honored by
+// ghupdate.CheckAndPrepareUpdate.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asaidimu/ghupdate"
+)
+
+// ErrUsage is returned by Run when the arguments do not form a valid subcommand.
+// The usage text has been written to the output by then.
+var ErrUsage = errors.New("invalid update command")
+
+// ErrCancelled is returned by Run when the user declines a confirmation prompt.
+var ErrCancelled = errors.New("update command cancelled")
+
+// Run executes the update subcommand given by args, the arguments following "update"
+// (e.g., []string{"pin", "v1.4.2"}), writing messages to out (os.Stdout if nil) and reading
+// confirmations from os.Stdin.
+//
+// It returns an error wrapping ErrUsage for invalid arguments, or the error of the subcommand.
+func Run(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	return RunWithInput(config, args, nil, out)
+}
+
+// RunWithInput behaves like Run, reading confirmations from in (os.Stdin if nil) instead.
+func RunWithInput(config ghupdate.UpdateConfig, args []string, in io.Reader, out io.Writer) error {
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	if len(args) == 0 {
+		return usage(out)
+	}
+
+	switch args[0] {
+	case "channel":
+		return channel(config, args[1:], out)
+	case "pin":
+		return pin(config, args[1:], out)
+	case "unpin":
+		return unpin(config, args[1:], out)
+	case "backups":
+		return backups(config, args[1:], out)
+	case "rollback":
+		return rollback(config, args[1:], in, out)
+	case "reset":
+		return reset(config, args[1:], out)
+	default:
+		return usage(out)
+	}
+}
+
+// Usage writes the usage text of the update subcommands to w.
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "usage:")
+	fmt.Fprintln(w, "  update channel [<name> [--allow-downgrade]]")
+	fmt.Fprintln(w, "  update pin <version>")
+	fmt.Fprintln(w, "  update unpin")
+	fmt.Fprintln(w, "  update backups")
+	fmt.Fprintln(w, "  update rollback [<version>] [--yes]")
+	fmt.Fprintln(w, "  update reset [--dry-run]")
+}
+
+// usage writes the usage text to out and returns ErrUsage.
+func usage(out io.Writer) error {
+	Usage(out)
+	return ErrUsage
+}
+
+// channel shows the current channel, or switches to another one.
+func channel(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("channel", flag.ContinueOnError)
+	fs.SetOutput(out)
+	allowDowngrade := fs.Bool("allow-downgrade", false, "allow moving to an older release when the channel has no newer one")
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		// Accept the channel name before flags, as in "channel stable --allow-downgrade"
+		args = append(args[1:], args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+
+	switch fs.NArg() {
+	case 0:
+		current, err := ghupdate.Channel(config.DataDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Update channel: %s\n", current)
+		return nil
+	case 1:
+	default:
+		return usage(out)
+	}
+
+	name := fs.Arg(0)
+	release, err := ghupdate.SwitchChannel(config, name, *allowDowngrade)
+	if errors.Is(err, ghupdate.ErrChannelDowngrade) {
+		fmt.Fprintf(out, "The %s channel has no release at or above %s; rerun with --allow-downgrade to switch anyway.\n", name, config.CurrentVersion)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Switched to the %s channel (newest release: %s).\n", name, release.TagName)
+	return nil
+}
+
+// pin pins the installation to a version.
+func pin(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	if len(args) != 1 || args[0] == "" {
+		return usage(out)
+	}
+	if err := ghupdate.Pin(config.DataDir, args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Pinned to %s; updates will install this version only.\n", args[0])
+	return nil
+}
+
+// unpin removes the version pin.
+func unpin(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return usage(out)
+	}
+	pinned, err := ghupdate.PinnedVersion(config.DataDir)
+	if err != nil {
+		return err
+	}
+	if pinned == "" {
+		fmt.Fprintln(out, "No version is pinned.")
+		return nil
+	}
+	if err := ghupdate.Unpin(config.DataDir); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Unpinned from %s; updates follow the channel again.\n", pinned)
+	return nil
+}
+
+// backups lists the versions kept for rolling back.
+func backups(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return usage(out)
+	}
+	kept, err := ghupdate.ListBackups(config.DataDir)
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		fmt.Fprintln(out, "No previous versions are kept.")
+		return nil
+	}
+	for i := len(kept) - 1; i >= 0; i-- {
+		fmt.Fprintf(out, "%s\t%s\n", kept[i].Version, kept[i].CreatedAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// rollback restores the previous version, or the given kept one, after asking for confirmation
+// on in unless --yes is given.
+func rollback(config ghupdate.UpdateConfig, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	fs.SetOutput(out)
+	yes := fs.Bool("yes", false, "restore without asking for confirmation")
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		// Accept the version before flags, as in "rollback v1.4.1 --yes"
+		args = append(args[1:], args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if fs.NArg() > 1 {
+		return usage(out)
+	}
+
+	kept, err := ghupdate.ListBackups(config.DataDir)
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		fmt.Fprintln(out, "No previous version to roll back to.")
+		return ghupdate.ErrNoBackup
+	}
+	version := fs.Arg(0)
+	if version == "" {
+		version = kept[len(kept)-1].Version
+	}
+
+	if !*yes {
+		fmt.Fprintf(out, "Restore %s in place of %s? [y/N] ", version, config.CurrentVersion)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(out, "Rollback cancelled.")
+			return ErrCancelled
+		}
+	}
+
+	err = ghupdate.RestoreBackup(config, version)
+	if errors.Is(err, ghupdate.ErrNoBackup) {
+		fmt.Fprintf(out, "%s is not kept; see \"update backups\".\n", version)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Restored %s; restart to use it. Run \"update pin %s\" to stay on it.\n", version, version)
+	return nil
+}
+
+// reset removes all updater state to recover from corruption, or lists what would be removed
+// with --dry-run.
+func reset(config ghupdate.UpdateConfig, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dryRun := fs.Bool("dry-run", false, "list the files that would be removed without removing them")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if fs.NArg() != 0 {
+		return usage(out)
+	}
+
+	removed, err := ghupdate.ResetState(config, *dryRun)
+	for _, path := range removed {
+		if *dryRun {
+			fmt.Fprintf(out, "Would remove %s\n", path)
+		} else {
+			fmt.Fprintf(out, "Removed %s\n", path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Fprintln(out, "No updater state to reset.")
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Command ghupdate-sign generates Ed25519 signing keys, signed release manifests and signed
+// update bundles for ghupdate.
+//
+// Usage:
+//
+//	ghupdate-sign keygen -out release-key
+//	ghupdate-sign sign -key release-key -version v1.2.3 [-out dist/ghupdate-manifest.json] dist/*
+//	ghupdate-sign bundle -key release-key -version v1.2.3 -out myapp-v1.2.3.bundle dist/myapp
+//
+// keygen writes a base64-encoded private key to the given path and the matching public key to
+// the same path plus ".pub". The public key is embedded in applications via
+// ghupdate.DecodePublicKey and UpdateConfig.ManifestPublicKey.
+//
+// sign hashes the given release files and writes the manifest and its signature (".sig"),
+// both of which must be uploaded to the release alongside the assets.
+//
+// bundle packs a single executable together with its signed manifest into one file that
+// consumers install offline with ghupdate.PrepareBundle or ghupdate.ApplyBundle.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/asaidimu/ghupdate"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "sign":
+		err = sign(os.Args[2:])
+	case "bundle":
+		err = bundle(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ghupdate-sign: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  ghupdate-sign keygen -out <key-file>")
+	fmt.Fprintln(os.Stderr, "  ghupdate-sign sign -key <key-file> -version <tag> [-out <manifest>] <files...>")
+	fmt.Fprintln(os.Stderr, "  ghupdate-sign bundle -key <key-file> -version <tag> -out <bundle> <executable>")
+}
+
+// keygen generates a new Ed25519 key pair.
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", "ghupdate-key", "path of the private key file; the public key is written to <out>.pub")
+	fs.Parse(args)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	if err := os.WriteFile(*out, []byte(base64.StdEncoding.EncodeToString(priv)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(*out+".pub", []byte(base64.StdEncoding.EncodeToString(pub)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	fmt.Printf("Wrote private key to %s and public key to %s.pub\n", *out, *out)
+	return nil
+}
+
+// sign generates and signs a manifest for the given release files.
+func sign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path of the private key file")
+	version := fs.String("version", "", "release tag the manifest is generated for (e.g. v1.2.3)")
+	out := fs.String("out", ghupdate.DefaultManifestAssetName, "path of the manifest to write; the signature is written to <out>.sig")
+	fs.Parse(args)
+
+	if *keyPath == "" || *version == "" || fs.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	key, err := readPrivateKey(*keyPath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := ghupdate.GenerateManifest(*version, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	data, sig, err := ghupdate.SignManifest(manifest, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.WriteFile(*out+".sig", sig, 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	fmt.Printf("Signed %d assets for %s into %s\n", len(manifest.Assets), *version, *out)
+	return nil
+}
+
+// bundle creates a signed update bundle for a single executable.
+func bundle(args []string) error {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path of the private key file")
+	version := fs.String("version", "", "version of the bundled executable (e.g. v1.2.3)")
+	out := fs.String("out", "", "path of the bundle to write")
+	fs.Parse(args)
+
+	if *keyPath == "" || *version == "" || *out == "" || fs.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	key, err := readPrivateKey(*keyPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	if err := ghupdate.CreateBundle(f, fs.Arg(0), *version, key); err != nil {
+		os.Remove(*out)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Wrote bundle for %s to %s\n", *version, *out)
+	return nil
+}
+
+// readPrivateKey reads a base64-encoded private key file written by keygen.
+func readPrivateKey(path string) (ed25519.PrivateKey, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	return ghupdate.DecodePrivateKey(string(encoded))
+}
@@ -0,0 +1,36 @@
+package ghupdate
+
+import (
+	"context"
+	"sync"
+)
+
+// runConcurrently runs independent tasks in parallel. Each task receives a context derived from ctx
+// that is cancelled as soon as any task fails, so its siblings can stop early instead of finishing
+// work whose result will be discarded. It waits for all tasks to return.
+//
+// It returns the error of the first task to fail, or nil if all succeed.
+func runConcurrently(ctx context.Context, tasks ...func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := task(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
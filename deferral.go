@@ -0,0 +1,42 @@
+package ghupdate
+
+import "time"
+
+// deferral records a "remind me later" choice for one version.
+type deferral struct {
+	// Version is the deferred version.
+	Version string `json:"version"`
+	// Until is the time the deferral expires.
+	Until time.Time `json:"until"`
+}
+
+// DeferUpdate records that the user asked to be reminded about version later: until the given time,
+// updates to version are still staged but reported with UpdateInfo.DeferredUntil set, and
+// RunScheduler does not announce them. A newer version is reported as usual. Only the most recent
+// deferral is kept; a zero until clears it.
+//
+// It returns an error if the updater state in dataDir cannot be read or written.
+func DeferUpdate(dataDir, version string, until time.Time) error {
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.Deferral = nil
+		if !until.IsZero() {
+			state.Deferral = &deferral{Version: version, Until: until.Round(0).UTC()}
+		}
+	})
+}
+
+// deferredUntil returns the time until which the update to version is deferred, or the zero time
+// if it is not deferred or the deferral has expired.
+func deferredUntil(config UpdateConfig, version string) time.Time {
+	if config.DataDir == "" {
+		return time.Time{}
+	}
+	state, err := loadState(config.DataDir)
+	if err != nil || state.Deferral == nil {
+		return time.Time{}
+	}
+	if compareVersions(config, state.Deferral.Version, version) != 0 || !time.Now().Before(state.Deferral.Until) {
+		return time.Time{}
+	}
+	return state.Deferral.Until
+}
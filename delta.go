@@ -0,0 +1,187 @@
+package ghupdate
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// DeltaFormat is a binary patch format delta updates can be applied with.
+type DeltaFormat struct {
+	// Name identifies the format, e.g. "bsdiff".
+	Name string
+	// Pattern is the pattern of patch assets in this format, with the placeholders of AssetPattern
+	// plus {from} and {from_no_v} for CurrentVersion, e.g. "myapp-{from}-to-{version}-{os}-{arch}.patch".
+	Pattern string
+	// Apply applies the patch at patchPath to the executable at oldPath, writing the new file to w.
+	Apply func(oldPath, patchPath string, w io.Writer) error
+	// Tool is the external program Apply runs, if any. The format is skipped on machines where it
+	// is not found in PATH.
+	Tool string
+}
+
+// BsdiffFormat returns the DeltaFormat of patches created by bsdiff 4.x, named after pattern.
+// Patches are applied in-process.
+func BsdiffFormat(pattern string) DeltaFormat {
+	return DeltaFormat{Name: "bsdiff", Pattern: pattern, Apply: applyBsdiff}
+}
+
+// ZstdPatchFormat returns the DeltaFormat of patches created with "zstd --patch-from", named after
+// pattern. Patches are applied by the zstd tool, and are skipped on machines without it. The patch
+// must have been created with a window large enough for the executable (--long or -M, see zstd(1)),
+// which is allowed up to 2 GiB when applying it.
+func ZstdPatchFormat(pattern string) DeltaFormat {
+	return DeltaFormat{Name: "zstd", Pattern: pattern, Apply: applyZstdPatch, Tool: "zstd"}
+}
+
+// applyBsdiff applies a bsdiff patch (see bspatch).
+func applyBsdiff(oldPath, patchPath string, w io.Writer) error {
+	old, err := os.Open(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to open current executable: %w", err)
+	}
+	defer old.Close()
+	oldInfo, err := old.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat current executable: %w", err)
+	}
+
+	patch, err := os.Open(patchPath)
+	if err != nil {
+		return fmt.Errorf("failed to open patch: %w", err)
+	}
+	defer patch.Close()
+	patchInfo, err := patch.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat patch: %w", err)
+	}
+
+	return bspatch(old, oldInfo.Size(), patch, patchInfo.Size(), w)
+}
+
+// applyZstdPatch applies a zstd patch with the zstd tool.
+func applyZstdPatch(oldPath, patchPath string, w io.Writer) error {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		return fmt.Errorf("the zstd tool is required to apply zstd patches: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("zstd", "-d", "-c", "--long=31", "--patch-from="+oldPath, patchPath)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("zstd failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// deltaFormats returns the configured delta formats: bsdiff patches named after DeltaPattern, if set,
+// followed by DeltaFormats.
+func deltaFormats(config UpdateConfig) []DeltaFormat {
+	var formats []DeltaFormat
+	if config.DeltaPattern != "" {
+		formats = append(formats, BsdiffFormat(config.DeltaPattern))
+	}
+	return append(formats, config.DeltaFormats...)
+}
+
+// deltaCandidate is a published patch in one of the configured delta formats.
+type deltaCandidate struct {
+	format DeltaFormat
+	asset  *GitHubAsset
+}
+
+// findDeltaAssets returns the patch assets of release that turn the running version into the full
+// asset, one per configured format at most, smallest first. Patches that would not save bandwidth,
+// or whose format needs a tool that is not installed, are left out.
+func findDeltaAssets(config UpdateConfig, release *GitHubRelease, full *GitHubAsset) []deltaCandidate {
+	targetOS, targetArch := resolvePlatform(config)
+	from := strings.NewReplacer(
+		"{from}", config.CurrentVersion,
+		"{from_no_v}", strings.TrimPrefix(config.CurrentVersion, "v"),
+	)
+
+	var candidates []deltaCandidate
+	for _, format := range deltaFormats(config) {
+		if format.Pattern == "" || format.Apply == nil || (format.Tool != "" && !toolInstalled(format.Tool)) {
+			continue
+		}
+		patchConfig := config
+		patchConfig.AssetPattern = from.Replace(format.Pattern)
+		for _, name := range assetNames(patchConfig, release.TagName, targetOS, targetArch, true) {
+			if matches := matchAssets(release.Assets, name); len(matches) == 1 {
+				if matches[0].Size > 0 && matches[0].Size < full.Size {
+					candidates = append(candidates, deltaCandidate{format: format, asset: &matches[0]})
+				}
+				break
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b deltaCandidate) int {
+		return cmp.Compare(a.asset.Size, b.asset.Size)
+	})
+	return candidates
+}
+
+// applyDeltaUpdate reconstructs the full asset of release at destPath from the running executable
+// and a published patch (see findDeltaAssets). Patches are tried smallest first; each result must
+// hash to sum, the expected SHA-256 of the full asset, so a patch never yields anything a full
+// download would not. A patch that cannot be downloaded or applied, or yields the wrong hash, is
+// skipped in favor of the next.
+//
+// It reports whether destPath holds the reconstructed asset; on failure, nothing is left behind and
+// the caller falls back to a full download. A partial download already at destPath is left to be resumed.
+func applyDeltaUpdate(config UpdateConfig, release *GitHubRelease, full *GitHubAsset, sum, destPath string) bool {
+	if sum == "" || archiveFormat(full.Name) != "" {
+		return false
+	}
+	// An interrupted full download is resumed instead
+	if _, err := os.Stat(destPath); err == nil {
+		return false
+	}
+
+	patchPath := destPath + ".patch"
+	defer os.Remove(patchPath)
+	for _, candidate := range findDeltaAssets(config, release, full) {
+		os.Remove(patchPath)
+		if _, err := downloadReleaseAsset(config, candidate.asset, patchPath, true); err != nil {
+			continue
+		}
+		if err := patchExecutable(config, candidate.format, config.ExecutablePath, patchPath, destPath, sum); err != nil {
+			os.Remove(destPath)
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// patchExecutable applies the patch at patchPath to the executable at oldPath with format, writing
+// the result to destPath with the configured FileMode.
+//
+// It returns an error if the patch cannot be applied or the result does not hash to sum.
+func patchExecutable(config UpdateConfig, format DeltaFormat, oldPath, patchPath, destPath, sum string) error {
+	out, err := openStagedFile(config, destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", destPath, err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	if err := format.Apply(oldPath, patchPath, io.MultiWriter(out, hash)); err != nil {
+		return fmt.Errorf("failed to apply %s patch: %w", format.Name, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", destPath, err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != sum {
+		return fmt.Errorf("patched executable has SHA-256 %s, expected %s", actual, sum)
+	}
+	return nil
+}
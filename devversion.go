@@ -0,0 +1,52 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ErrDevVersion is returned by CheckAndPrepareUpdate when the running version is a development build
+// and UpdateConfig.DevVersionBehavior is DevVersionError.
+var ErrDevVersion = errors.New("running a development build")
+
+// DevVersionBehavior selects how CheckAndPrepareUpdate treats development builds, whose
+// CurrentVersion is "dev", "(devel)" or, with the built-in semantic version comparison, not a valid
+// semantic version. Such versions cannot be meaningfully compared with release tags.
+type DevVersionBehavior string
+
+const (
+	// DevVersionError makes checks fail with ErrDevVersion. It is the default.
+	DevVersionError DevVersionBehavior = "error"
+	// DevVersionSkip reports that no update is available.
+	DevVersionSkip DevVersionBehavior = "skip"
+	// DevVersionAlwaysUpdate treats the newest release as an update, bypassing downgrade protection,
+	// so development builds can exercise the update flow.
+	DevVersionAlwaysUpdate DevVersionBehavior = "always-update"
+)
+
+// isDevVersion reports whether version denotes a development build (see DevVersionBehavior).
+func isDevVersion(config UpdateConfig, version string) bool {
+	if version == "dev" || version == "(devel)" {
+		return true
+	}
+	if hasCustomComparator(config) {
+		return false
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return !semver.IsValid(version)
+}
+
+// validateDevVersionBehavior checks that behavior is one of the DevVersionBehavior constants.
+// It returns an error for unknown behaviors.
+func validateDevVersionBehavior(behavior DevVersionBehavior) error {
+	switch behavior {
+	case "", DevVersionError, DevVersionSkip, DevVersionAlwaysUpdate:
+		return nil
+	}
+	return fmt.Errorf("unknown DevVersionBehavior %q", behavior)
+}
@@ -0,0 +1,63 @@
+package ghupdate
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// showProgressDialog opens a minimal native window telling the user that an update is being
+// installed, without cgo: PowerShell with Windows Forms on Windows, osascript on macOS, and
+// zenity where available on other desktops. The dialog cannot be dismissed into cancelling the update.
+//
+// It returns a function that closes the dialog. If no dialog can be shown, the returned function
+// does nothing.
+func showProgressDialog(title, message string) func() {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		script := `Add-Type -AssemblyName System.Windows.Forms;` +
+			`$f = New-Object Windows.Forms.Form; $f.Text = $env:GHUPDATE_TITLE;` +
+			`$f.Width = 360; $f.Height = 130; $f.FormBorderStyle = 'FixedDialog'; $f.ControlBox = $false;` +
+			`$f.StartPosition = 'CenterScreen'; $f.TopMost = $true;` +
+			`$l = New-Object Windows.Forms.Label; $l.Text = $env:GHUPDATE_MESSAGE; $l.Left = 12; $l.Top = 12; $l.Width = 320;` +
+			`$p = New-Object Windows.Forms.ProgressBar; $p.Style = 'Marquee'; $p.Left = 12; $p.Top = 44; $p.Width = 320;` +
+			`$f.Controls.Add($l); $f.Controls.Add($p); [void]$f.ShowDialog()`
+		cmd = powerShellCommand(script, title, message)
+	case "darwin":
+		script := `display dialog ` + appleScriptQuote(message) + ` with title ` + appleScriptQuote(title) +
+			` buttons {"Please wait"} giving up after 600`
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		if _, err := exec.LookPath("zenity"); err != nil {
+			return func() {}
+		}
+		cmd = exec.Command("zenity", "--progress", "--pulsate", "--no-cancel", "--auto-close",
+			"--title="+title, "--text="+message)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return func() {}
+	}
+	return func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+}
+
+// powerShellCommand returns a command running script in a hidden PowerShell window, with title and
+// message available to it as $env:GHUPDATE_TITLE and $env:GHUPDATE_MESSAGE. They may come from
+// release tags, which nothing signs, so they are never spliced into the script: PowerShell ends
+// string literals at typographic quotes (U+2018 to U+201E) as well as ASCII ones, which makes
+// quoting them error-prone.
+func powerShellCommand(script, title, message string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-WindowStyle", "Hidden", "-Command", script)
+	cmd.Env = append(os.Environ(), "GHUPDATE_TITLE="+title, "GHUPDATE_MESSAGE="+message)
+	return cmd
+}
+
+// appleScriptQuote quotes s as an AppleScript string literal.
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
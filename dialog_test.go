@@ -0,0 +1,22 @@
+package ghupdate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPowerShellCommandKeepsTextOutOfScript(t *testing.T) {
+	title := "myapp v1.2.3’); Start-Process calc; ('"
+	message := "Update to v1.2.3'; Remove-Item -Recurse ~; '"
+	cmd := powerShellCommand("Write-Output $env:GHUPDATE_TITLE $env:GHUPDATE_MESSAGE", title, message)
+
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "Start-Process") || strings.Contains(arg, "Remove-Item") {
+			t.Errorf("argument %q carries the notification text", arg)
+		}
+	}
+	if !slices.Contains(cmd.Env, "GHUPDATE_TITLE="+title) || !slices.Contains(cmd.Env, "GHUPDATE_MESSAGE="+message) {
+		t.Errorf("environment lacks the title and message")
+	}
+}
@@ -0,0 +1,97 @@
+package ghupdate
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// verifyAssetDigest checks the file at path against a digest in the "algorithm:hex" form
+// reported by the GitHub API (e.g., "sha256:2c26b46b..."). sha256 and sha512 are supported.
+// If knownSHA256 is set, it is the hex-encoded SHA-256 of the file computed while it was written,
+// and sha256 digests are checked against it without reading the file again.
+//
+// It returns an error if the digest is malformed, uses an unsupported algorithm, or does not match.
+func verifyAssetDigest(path, digest, knownSHA256 string) error {
+	algorithm, expected, ok := strings.Cut(digest, ":")
+	if !ok || expected == "" {
+		return fmt.Errorf("malformed asset digest %q", digest)
+	}
+
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported asset digest algorithm %q", algorithm)
+	}
+
+	actual := knownSHA256
+	if actual == "" || strings.ToLower(algorithm) != "sha256" {
+		var err error
+		if actual, err = hashFile(path, h); err != nil {
+			return err
+		}
+	}
+	if actual != strings.ToLower(expected) {
+		return fmt.Errorf("digest mismatch for %q: expected %s, got %s:%s", path, digest, algorithm, actual)
+	}
+	return nil
+}
+
+// hashFile feeds the content of the file at path through h and returns the hex-encoded sum.
+func hashFile(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %q for hashing: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %q: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFilePrefix feeds the first n bytes of the file at path through h.
+//
+// It returns the number of bytes hashed, or an error if the file cannot be read.
+func hashFilePrefix(path string, n int64, h hash.Hash) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %q for hashing: %w", path, err)
+	}
+	defer f.Close()
+
+	read, err := io.Copy(h, io.LimitReader(f, n))
+	if err != nil {
+		return read, fmt.Errorf("failed to hash %q: %w", path, err)
+	}
+	return read, nil
+}
+
+// sameFileContent reports whether the files at a and b exist and have identical content.
+func sameFileContent(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil || infoA.Size() != infoB.Size() {
+		return false
+	}
+
+	hashA, err := hashFile(a, sha256.New())
+	if err != nil {
+		return false
+	}
+	hashB, err := hashFile(b, sha256.New())
+	return err == nil && hashA == hashB
+}
@@ -0,0 +1,45 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diskSpaceMargin is the minimum free space kept in addition to the download size.
+const diskSpaceMargin = 16 << 20
+
+// ErrInsufficientDiskSpace is returned when the staging file system has too little free space for a download.
+var ErrInsufficientDiskSpace = errors.New("insufficient disk space")
+
+// checkDiskSpace verifies that the file system holding dir has room for size more bytes, plus a
+// margin of 10% or 16 MiB, whichever is larger. If dir does not exist yet, its nearest existing
+// parent is checked. Platforms where free space cannot be determined always pass.
+//
+// It returns an error wrapping ErrInsufficientDiskSpace if there is not enough free space.
+func checkDiskSpace(dir string, size int64) error {
+	if size <= 0 {
+		return nil
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+
+	available, ok := availableDiskSpace(dir)
+	if !ok {
+		return nil
+	}
+	required := uint64(size) + max(uint64(size)/10, diskSpaceMargin)
+	if available < required {
+		return fmt.Errorf("%w in %s: %d bytes available, %d bytes required", ErrInsufficientDiskSpace, dir, available, required)
+	}
+	return nil
+}
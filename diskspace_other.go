@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !openbsd && !windows
+
+package ghupdate
+
+// diskSpaceSupported reports whether availableDiskSpace can query free space on this platform.
+const diskSpaceSupported = false
+
+// availableDiskSpace reports that free space cannot be determined on this platform.
+func availableDiskSpace(dir string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package ghupdate
+
+import "syscall"
+
+// diskSpaceSupported reports whether availableDiskSpace can query free space on this platform.
+const diskSpaceSupported = true
+
+// availableDiskSpace returns the number of bytes available to unprivileged users on the file system holding dir.
+// It returns false if the file system cannot be queried.
+func availableDiskSpace(dir string) (uint64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, false
+	}
+	return uint64(max(int64(st.Bavail), 0)) * uint64(st.Bsize), true
+}
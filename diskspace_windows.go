@@ -0,0 +1,26 @@
+package ghupdate
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// diskSpaceSupported reports whether availableDiskSpace can query free space on this platform.
+const diskSpaceSupported = true
+
+var procGetDiskFreeSpaceExW = modKernel32.NewProc("GetDiskFreeSpaceExW")
+
+// availableDiskSpace returns the number of bytes available to the current user on the volume holding dir.
+// It returns false if the volume cannot be queried.
+func availableDiskSpace(dir string) (uint64, bool) {
+	path, err := syscall.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, false
+	}
+	var available uint64
+	r, _, _ := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(&available)), 0, 0)
+	if r == 0 {
+		return 0, false
+	}
+	return available, true
+}
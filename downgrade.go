@@ -0,0 +1,48 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDowngradeRefused is returned when a release is older than the highest version previously
+// installed and UpdateConfig.AllowDowngrade is not set.
+var ErrDowngradeRefused = errors.New("refusing to downgrade below the highest installed version")
+
+// recordInstalledVersion records config.CurrentVersion as installed if it is higher than any version
+// seen before, and returns the highest version ever installed.
+func recordInstalledVersion(config UpdateConfig) (string, error) {
+	state, err := loadState(config.DataDir)
+	if err != nil {
+		return "", err
+	}
+
+	if state.HighestVersion != "" && compareVersions(config, config.CurrentVersion, state.HighestVersion) <= 0 {
+		return state.HighestVersion, nil
+	}
+
+	state.HighestVersion = config.CurrentVersion
+	if err := saveState(config, state); err != nil {
+		return "", err
+	}
+	return state.HighestVersion, nil
+}
+
+// checkDowngrade refuses releases older than the highest version ever installed, protecting against
+// compromised or mis-tagged releases that would silently move users backwards.
+//
+// It returns an error wrapping ErrDowngradeRefused unless AllowDowngrade is set.
+func checkDowngrade(config UpdateConfig, version string) error {
+	if config.AllowDowngrade {
+		return nil
+	}
+
+	highest, err := recordInstalledVersion(config)
+	if err != nil {
+		return err
+	}
+	if compareVersions(config, version, highest) < 0 {
+		return fmt.Errorf("%w: release %s is older than previously installed %s", ErrDowngradeRefused, version, highest)
+	}
+	return nil
+}
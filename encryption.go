@@ -0,0 +1,250 @@
+package ghupdate
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// encryptionMagic identifies files written by encryptFile.
+var encryptionMagic = []byte("GHUENC1\x00")
+
+const (
+	// encryptionChunkSize is the plaintext size of each sealed chunk. Chunking keeps memory
+	// usage flat regardless of the size of the staged executable.
+	encryptionChunkSize = 64 * 1024
+	// encryptionPrefixSize is the size of the random per-file nonce prefix.
+	encryptionPrefixSize = 7
+)
+
+// encryptedUpdatePath returns the path at which an encrypted prepared update is staged
+// inside the given data directory.
+func encryptedUpdatePath(dataDir string) string {
+	return filepath.Join(dataDir, "update.enc")
+}
+
+// stagingKey resolves the key used to encrypt staged updates at rest.
+// It uses config.StagingKey when provided, and otherwise falls back to a key kept in the
+// OS keystore under the "ghupdate" service, scoped to the configured repository.
+func stagingKey(config UpdateConfig) ([]byte, error) {
+	keyFunc := config.StagingKey
+	if keyFunc == nil {
+		keyFunc = OSKeystoreKey("ghupdate", config.GitHubOwner+"/"+config.GitHubRepo)
+	}
+
+	key, err := keyFunc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain staging key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("staging key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// encryptStagedUpdate encrypts the plain staged update at updatePath into the encrypted staging
+// location and removes the plaintext copy.
+func encryptStagedUpdate(config UpdateConfig, updatePath string) error {
+	key, err := stagingKey(config)
+	if err != nil {
+		return err
+	}
+
+	encPath := encryptedUpdatePath(config.DataDir)
+	if err := encryptFile(updatePath, encPath, key); err != nil {
+		os.Remove(encPath)
+		return fmt.Errorf("failed to encrypt staged update: %w", err)
+	}
+
+	if err := os.Remove(updatePath); err != nil {
+		return fmt.Errorf("failed to remove unencrypted staged update: %w", err)
+	}
+	return nil
+}
+
+// decryptStagedUpdate restores the plain staged update at updatePath from its encrypted form.
+// The encrypted copy is removed once decryption succeeds.
+func decryptStagedUpdate(config UpdateConfig, updatePath string) error {
+	encPath := encryptedUpdatePath(config.DataDir)
+	if _, err := os.Stat(encPath); os.IsNotExist(err) {
+		return fmt.Errorf("no prepared update found at %s", encPath)
+	}
+
+	key, err := stagingKey(config)
+	if err != nil {
+		return err
+	}
+
+	// Decrypt next to the staged update and rename it into place, since a previous update process
+	// may still be running from that path, which cannot be written to (ETXTBSY)
+	tmpPath := updatePath + ".tmp"
+	if err := decryptFile(encPath, tmpPath, key, fileMode(config)); err != nil {
+		return fmt.Errorf("failed to decrypt staged update: %w", err)
+	}
+	if err := os.Rename(tmpPath, updatePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move decrypted update into place: %w", err)
+	}
+
+	return os.Remove(encPath)
+}
+
+// encryptFile encrypts src into dst using AES-256-GCM.
+// The plaintext is split into fixed-size chunks, each sealed with a nonce derived from a random
+// per-file prefix, the chunk counter and a final-chunk flag, so truncation and reordering of
+// chunks are detected on decryption.
+//
+// It returns an error if the key is invalid or if reading or writing either file fails.
+func encryptFile(src, dst string, key []byte) error {
+	aead, err := newStagingAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %q for encryption: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create encrypted file %q: %w", dst, err)
+	}
+	defer out.Close()
+
+	prefix := make([]byte, encryptionPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+	if _, err := out.Write(append(append([]byte{}, encryptionMagic...), prefix...)); err != nil {
+		return fmt.Errorf("failed to write encryption header to %q: %w", dst, err)
+	}
+
+	// Read one chunk ahead so the final chunk can be flagged as such.
+	buf := make([]byte, encryptionChunkSize)
+	next := make([]byte, encryptionChunkSize)
+	n, err := io.ReadFull(in, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("failed to read %q: %w", src, err)
+	}
+
+	for counter := uint32(0); ; counter++ {
+		m, err := io.ReadFull(in, next)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read %q: %w", src, err)
+		}
+		last := m == 0
+
+		sealed := aead.Seal(nil, chunkNonce(prefix, counter, last), buf[:n], nil)
+		if _, err := out.Write(sealed); err != nil {
+			return fmt.Errorf("failed to write encrypted data to %q: %w", dst, err)
+		}
+		if last {
+			break
+		}
+		buf, next = next, buf
+		n = m
+	}
+
+	return out.Close()
+}
+
+// decryptFile decrypts a file produced by encryptFile into dst, creating it with the given mode.
+// Partially written output is removed if authentication of any chunk fails.
+//
+// It returns an error if the file is not a valid encrypted update or if the key does not match.
+func decryptFile(src, dst string, key []byte, mode os.FileMode) (err error) {
+	aead, err := newStagingAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open encrypted file %q: %w", src, err)
+	}
+	defer in.Close()
+
+	header := make([]byte, len(encryptionMagic)+encryptionPrefixSize)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return fmt.Errorf("failed to read encryption header from %q: %w", src, err)
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], encryptionMagic) {
+		return fmt.Errorf("%q is not an encrypted update file", src)
+	}
+	prefix := header[len(encryptionMagic):]
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create decrypted file %q: %w", dst, err)
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	sealedSize := encryptionChunkSize + aead.Overhead()
+	buf := make([]byte, sealedSize)
+	next := make([]byte, sealedSize)
+	n, err := io.ReadFull(in, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("failed to read encrypted data from %q: %w", src, err)
+	}
+
+	for counter := uint32(0); ; counter++ {
+		m, err := io.ReadFull(in, next)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read encrypted data from %q: %w", src, err)
+		}
+		last := m == 0
+
+		plain, err := aead.Open(nil, chunkNonce(prefix, counter, last), buf[:n], nil)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %q: authentication failed", src)
+		}
+		if _, err := out.Write(plain); err != nil {
+			return fmt.Errorf("failed to write decrypted data to %q: %w", dst, err)
+		}
+		if last {
+			break
+		}
+		buf, next = next, buf
+		n = m
+	}
+
+	return out.Close()
+}
+
+// newStagingAEAD creates the AES-GCM cipher used for staging encryption.
+func newStagingAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staging key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AES-GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// chunkNonce builds the nonce for a chunk: the per-file prefix, the big-endian chunk
+// counter and a trailing byte set to 1 for the final chunk.
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, 12)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
@@ -0,0 +1,86 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// releaseCacheFileName is the name of the file in DataDir caching the last release API response.
+const releaseCacheFileName = "release-cache.json"
+
+// releaseCache is the last successful release API response together with its validators, used to
+// make conditional requests that GitHub answers with 304 Not Modified without consuming API quota.
+type releaseCache struct {
+	URL          string          `json:"url"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// loadReleaseCache returns the cached response for url from dataDir, or nil if there is none.
+func loadReleaseCache(dataDir, url string) *releaseCache {
+	if dataDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dataDir, releaseCacheFileName))
+	if err != nil {
+		return nil
+	}
+	var cache releaseCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.URL != url || len(cache.Body) == 0 {
+		return nil
+	}
+	return &cache
+}
+
+// saveReleaseCache stores a release response and its validators in config.DataDir. Responses
+// without an ETag or Last-Modified header are not cached, since they cannot be revalidated. The
+// file is only readable by its owner, since the response may come from a private repository.
+// Failures are ignored; the cache is only an optimization.
+func saveReleaseCache(config UpdateConfig, url string, header http.Header, body []byte) {
+	dataDir := config.DataDir
+	if dataDir == "" {
+		return
+	}
+	cache := releaseCache{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Body:         body,
+	}
+	if cache.ETag == "" && cache.LastModified == "" {
+		return
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dataDir, dirMode(config)); err != nil {
+		return
+	}
+	path := filepath.Join(dataDir, releaseCacheFileName)
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// setConditionalHeaders adds If-None-Match and If-Modified-Since headers from a cached response.
+func setConditionalHeaders(req *http.Request, cache *releaseCache) {
+	if cache == nil {
+		return
+	}
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
+}
@@ -0,0 +1,110 @@
+package ghupdate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// atomFeed is the subset of a GitHub releases.atom feed used to learn release tags.
+type atomFeed struct {
+	Entries []struct {
+		ID    string `xml:"id"`
+		Links []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+// fetchChannelReleaseFromFeed finds the newest release of channel in the repository's public
+// releases.atom feed, which costs no API quota and needs no token. Only if it is newer than
+// CurrentVersion is the full release fetched (see fetchReleaseByTag); otherwise the returned release
+// carries only its tag. The feed lists the ten most recent releases, without drafts.
+//
+// It returns an error if the feed cannot be fetched or has no release of the channel.
+func fetchChannelReleaseFromFeed(config UpdateConfig, channel string) (*GitHubRelease, error) {
+	var tags []string
+	err := withRetry(config, func() error {
+		var err error
+		tags, err = fetchFeedTags(config)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var newest string
+	for _, tag := range tags {
+		if !tagInChannel(config, tag, channel) {
+			continue
+		}
+		if newest == "" || compareVersions(config, tag, newest) > 0 {
+			newest = tag
+		}
+	}
+	if newest == "" {
+		return nil, fmt.Errorf("%w in channel %q", errNoReleases, channel)
+	}
+
+	if !isNewerVersion(config, config.CurrentVersion, newest) {
+		return &GitHubRelease{TagName: newest}, nil
+	}
+	return fetchReleaseByTag(config, newest)
+}
+
+// fetchFeedTags fetches https://github.com/{owner}/{repo}/releases.atom and returns the tags of its
+// entries, taken from their release page links (or, failing that, the last segment of their IDs).
+//
+// It returns an error if the request fails or the feed cannot be decoded.
+func fetchFeedTags(config UpdateConfig) ([]string, error) {
+	feedURL := fmt.Sprintf("https://github.com/%s/%s/releases.atom", config.GitHubOwner, config.GitHubRepo)
+	req, err := http.NewRequest("GET", feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request for %q: %w", feedURL, err)
+	}
+	setUserAgent(config, req)
+	req.Header.Set("Accept", "application/atom+xml")
+
+	client := httpClient(config, checkTimeout(config))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{URL: feedURL, StatusCode: resp.StatusCode}
+	}
+
+	var feed atomFeed
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxReleaseResponseSize)).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to decode release feed: %w", err)
+	}
+
+	var tags []string
+	for _, entry := range feed.Entries {
+		tag := ""
+		for _, link := range entry.Links {
+			if link.Rel != "" && link.Rel != "alternate" {
+				continue
+			}
+			if u, err := url.Parse(link.Href); err == nil {
+				if dir, last := path.Split(u.Path); strings.HasSuffix(dir, "/releases/tag/") {
+					tag = last
+				}
+			}
+		}
+		if tag == "" {
+			tag = entry.ID[strings.LastIndex(entry.ID, "/")+1:]
+		}
+		if tag != "" && len(tag) <= maxTagNameLength {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,53 @@
+package ghupdate
+
+import (
+	"os"
+	"time"
+)
+
+// defaultReplaceRetryTimeout is how long replacing the executable is retried while the file is
+// busy when UpdateConfig.ReplaceRetryTimeout is zero.
+const defaultReplaceRetryTimeout = 10 * time.Second
+
+// replaceRetryTimeout returns the effective replace retry timeout for timeout, the value of
+// UpdateConfig.ReplaceRetryTimeout.
+func replaceRetryTimeout(timeout time.Duration) time.Duration {
+	if timeout == 0 {
+		return defaultReplaceRetryTimeout
+	}
+	return max(timeout, 0)
+}
+
+// retryWhileBusy runs op until it succeeds, fails with an error other than a busy file (see
+// isFileBusy), or timeout has elapsed, backing off from 100ms to 2s between attempts. Antivirus
+// scanners, search indexers and Explorer commonly open a freshly written executable for a moment.
+//
+// It returns the error of the last attempt.
+func retryWhileBusy(timeout time.Duration, op func() error) error {
+	deadline := time.Now().Add(replaceRetryTimeout(timeout))
+	delay := 100 * time.Millisecond
+	for {
+		err := op()
+		if err == nil || !isFileBusy(err) || time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, 2*time.Second)
+	}
+}
+
+// openStagedFile opens path, where an update executable is staged, for writing with flag and the
+// configured FileMode. The update process runs from the staged file, so writing a newer update to
+// the same path fails with ETXTBSY on Unix until a previous update process has exited; opening is
+// retried for config.ReplaceRetryTimeout meanwhile.
+//
+// It returns the opened file, or the error of the last attempt.
+func openStagedFile(config UpdateConfig, path string, flag int) (*os.File, error) {
+	var f *os.File
+	err := retryWhileBusy(config.ReplaceRetryTimeout, func() error {
+		var err error
+		f, err = os.OpenFile(path, flag, fileMode(config))
+		return err
+	})
+	return f, err
+}
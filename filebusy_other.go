@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package ghupdate
+
+// isFileBusy reports false on platforms without a known busy-file error.
+func isFileBusy(err error) bool {
+	return false
+}
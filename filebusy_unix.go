@@ -0,0 +1,14 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isFileBusy reports whether err is ETXTBSY, returned when writing to an executable that is being
+// run, e.g. by another instance of the application.
+func isFileBusy(err error) bool {
+	return errors.Is(err, syscall.ETXTBSY)
+}
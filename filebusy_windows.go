@@ -0,0 +1,19 @@
+package ghupdate
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows error codes reported while another process has a file open.
+const (
+	errorAccessDenied     = syscall.Errno(5)
+	errorSharingViolation = syscall.Errno(32)
+	errorLockViolation    = syscall.Errno(33)
+)
+
+// isFileBusy reports whether err is a sharing or lock violation, or access being denied, as
+// reported while another process such as an antivirus scanner holds the file open.
+func isFileBusy(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation) || errors.Is(err, errorAccessDenied)
+}
@@ -0,0 +1,39 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// defaultFileMode is the permission used for staged executables when UpdateConfig.FileMode is unset.
+	defaultFileMode os.FileMode = 0755
+	// defaultDirMode is the permission used for created directories when UpdateConfig.DirMode is unset.
+	defaultDirMode os.FileMode = 0755
+)
+
+// fileMode returns the permission bits for files created by the updater.
+func fileMode(config UpdateConfig) os.FileMode {
+	if config.FileMode == 0 {
+		return defaultFileMode
+	}
+	return config.FileMode.Perm()
+}
+
+// dirMode returns the permission bits for directories created by the updater.
+func dirMode(config UpdateConfig) os.FileMode {
+	if config.DirMode == 0 {
+		return defaultDirMode
+	}
+	return config.DirMode.Perm()
+}
+
+// chmodWithUmask sets the permissions of path to mode with the process umask applied,
+// matching the permissions the file would have received had it been freshly created.
+// Unlike os.Chmod, this never grants bits that the umask would withhold.
+func chmodWithUmask(path string, mode os.FileMode) error {
+	if err := os.Chmod(path, mode&^currentUmask()); err != nil {
+		return fmt.Errorf("failed to set permissions for %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package ghupdate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultGoreleaserNameTemplate is goreleaser's default archive name_template.
+const defaultGoreleaserNameTemplate = `{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ with .Arm }}v{{ . }}{{ end }}{{ with .Mips }}_{{ . }}{{ end }}{{ if not (eq .Amd64 "v1") }}{{ .Amd64 }}{{ end }}`
+
+// goreleaserAction matches a template action, capturing its trim markers and pipeline.
+var goreleaserAction = regexp.MustCompile(`\{\{(-?)\s*(.*?)\s*(-?)\}\}`)
+
+// goreleaserEqCondition matches a comparison of .Os or .Arch with a literal, e.g. `eq .Arch "amd64"`.
+var goreleaserEqCondition = regexp.MustCompile(`^eq \.(Os|Arch) "([^"]*)"$`)
+
+// goreleaserMicroarchFields are template fields selecting CPU micro-architecture levels (GOAMD64,
+// GOARM64, GOMIPS, ...). Blocks conditional on them are dropped, assuming the default levels.
+var goreleaserMicroarchFields = []string{".Amd64", ".Arm64", ".Mips", ".I386", ".Ppc64", ".Riscv64"}
+
+// goreleaserTitleOS lists the GOOS values whose title-cased spelling is registered as an {os} alias
+// for templates using `title .Os`.
+var goreleaserTitleOS = []string{"linux", "darwin", "windows", "freebsd", "openbsd", "netbsd", "dragonfly", "solaris", "illumos", "aix", "android"}
+
+// goreleaserArmMarker stands for the ARM version digit while converting a template.
+const goreleaserArmMarker = "\x00arm\x00"
+
+// goreleaserArchiveExtensions maps goreleaser archive formats to asset name extensions.
+var goreleaserArchiveExtensions = map[string]string{
+	"tar.gz": ".tar.gz", "tgz": ".tgz", "tar.xz": ".tar.xz", "txz": ".txz", "tar.zst": ".tar.zst",
+	"tzst": ".tzst", "zip": ".zip", "gz": ".gz", "binary": "{ext}",
+}
+
+// ConfigureFromGoreleaser derives asset naming from a project's .goreleaser.yaml, so that
+// applications released with goreleaser need not reverse-engineer it: config.AssetPattern is set
+// from the name_template and format of the first archive, with format_overrides for the target
+// operating system applied, and OSAliases and ArchAliases are extended with the spellings the
+// template maps GOOS and GOARCH values to (e.g., "x86_64" for amd64). The project name defaults
+// to GitHubRepo. Embedding the file keeps the two in sync:
+//
+//	//go:embed .goreleaser.yaml
+//	var goreleaserConfig []byte
+//
+// Templates may use .ProjectName, .Version, .RawVersion, .Tag, .Os, .Arch and .Arm, the title and
+// tolower functions on them, and if/else chains comparing .Os or .Arch with literals. Blocks
+// depending on micro-architecture levels such as .Amd64 or .Mips are dropped, assuming the defaults.
+//
+// It returns an error if the file cannot be parsed, or its name template uses other fields or
+// functions; config is left unchanged in that case.
+func ConfigureFromGoreleaser(config *UpdateConfig, goreleaserYAML []byte) error {
+	doc, err := parseYAML(goreleaserYAML)
+	if err != nil {
+		return fmt.Errorf("failed to parse goreleaser config: %w", err)
+	}
+	root, _ := doc.(map[string]any)
+
+	projectName, _ := root["project_name"].(string)
+	if projectName == "" {
+		projectName = config.GitHubRepo
+	}
+	archive := map[string]any{}
+	if archives, ok := root["archives"].([]any); ok && len(archives) > 0 {
+		if first, ok := archives[0].(map[string]any); ok {
+			archive = first
+		}
+	}
+	nameTemplate, _ := archive["name_template"].(string)
+	if nameTemplate == "" {
+		nameTemplate = defaultGoreleaserNameTemplate
+	}
+
+	targetOS, _ := resolvePlatform(*config)
+	format := goreleaserFormat(archive)
+	if overrides, ok := archive["format_overrides"].([]any); ok {
+		for _, o := range overrides {
+			if override, ok := o.(map[string]any); ok && override["goos"] == targetOS {
+				format = goreleaserFormat(override)
+			}
+		}
+	}
+	ext, ok := goreleaserArchiveExtensions[format]
+	if !ok {
+		return fmt.Errorf("unsupported goreleaser archive format %q", format)
+	}
+
+	conv := &goreleaserConverter{projectName: projectName}
+	pattern, err := conv.convert(nameTemplate)
+	if err != nil {
+		return err
+	}
+
+	config.AssetPattern = pattern + ext
+	config.OSAliases = mergeAliases(config.OSAliases, conv.osAliases)
+	config.ArchAliases = mergeAliases(config.ArchAliases, conv.archAliases)
+	return nil
+}
+
+// goreleaserFormat returns the archive format of an archive or format override entry, from its
+// format field or the first of its formats, defaulting to "tar.gz".
+func goreleaserFormat(entry map[string]any) string {
+	if format, ok := entry["format"].(string); ok && format != "" {
+		return format
+	}
+	switch formats := entry["formats"].(type) {
+	case string:
+		return formats
+	case []any:
+		if len(formats) > 0 {
+			if format, ok := formats[0].(string); ok {
+				return format
+			}
+		}
+	}
+	return "tar.gz"
+}
+
+// mergeAliases returns aliases extended with extra, without duplicates.
+func mergeAliases(aliases, extra map[string][]string) map[string][]string {
+	if len(extra) == 0 {
+		return aliases
+	}
+	merged := make(map[string][]string, len(aliases)+len(extra))
+	for key, values := range aliases {
+		merged[key] = values
+	}
+	for key, values := range extra {
+		merged[key] = uniqueStrings(append(append([]string{}, merged[key]...), values...))
+	}
+	return merged
+}
+
+// goreleaserToken is a text or action token of a goreleaser name template.
+type goreleaserToken struct {
+	action bool
+	text   string
+}
+
+// goreleaserConverter converts a goreleaser name template into an AssetPattern, collecting the
+// {os} and {arch} aliases it implies.
+type goreleaserConverter struct {
+	projectName string
+	tokens      []goreleaserToken
+	pos         int
+	osAliases   map[string][]string
+	archAliases map[string][]string
+}
+
+// convert converts nameTemplate (see ConfigureFromGoreleaser).
+func (c *goreleaserConverter) convert(nameTemplate string) (string, error) {
+	c.tokens = tokenizeGoreleaserTemplate(nameTemplate)
+	pattern, stop, err := c.parse("")
+	if err != nil {
+		return "", err
+	}
+	if stop != "" {
+		return "", fmt.Errorf("unexpected {{ %s }} in goreleaser name template", stop)
+	}
+	if strings.Contains(pattern, goreleaserArmMarker) {
+		return "", fmt.Errorf("unsupported use of .Arm in goreleaser name template; use {{ with .Arm }}v{{ . }}{{ end }}")
+	}
+	return pattern, nil
+}
+
+// tokenizeGoreleaserTemplate splits a template into text and actions, applying trim markers.
+func tokenizeGoreleaserTemplate(tmpl string) []goreleaserToken {
+	var tokens []goreleaserToken
+	trimNext := false
+	last := 0
+	for _, m := range goreleaserAction.FindAllStringSubmatchIndex(tmpl, -1) {
+		text := tmpl[last:m[0]]
+		if trimNext {
+			text = strings.TrimLeft(text, " \t\r\n")
+		}
+		if m[3] > m[2] { // "{{-"
+			text = strings.TrimRight(text, " \t\r\n")
+		}
+		if text != "" {
+			tokens = append(tokens, goreleaserToken{text: text})
+		}
+		tokens = append(tokens, goreleaserToken{action: true, text: strings.Join(strings.Fields(tmpl[m[4]:m[5]]), " ")})
+		trimNext = m[7] > m[6] // "-}}"
+		last = m[1]
+	}
+	text := tmpl[last:]
+	if trimNext {
+		text = strings.TrimLeft(text, " \t\r\n")
+	}
+	if text != "" {
+		tokens = append(tokens, goreleaserToken{text: text})
+	}
+	return tokens
+}
+
+// parse converts tokens up to the next else or end action, which it returns, with dot standing for
+// the value of a surrounding with action.
+func (c *goreleaserConverter) parse(dot string) (string, string, error) {
+	var out strings.Builder
+	for c.pos < len(c.tokens) {
+		token := c.tokens[c.pos]
+		c.pos++
+		if !token.action {
+			out.WriteString(token.text)
+			continue
+		}
+
+		switch {
+		case token.text == "end" || token.text == "else" || strings.HasPrefix(token.text, "else "):
+			return out.String(), token.text, nil
+		case strings.HasPrefix(token.text, "if ") || strings.HasPrefix(token.text, "with "):
+			value, err := c.parseBlock(token.text, dot)
+			if err != nil {
+				return "", "", err
+			}
+			out.WriteString(value)
+		default:
+			value, err := c.eval(token.text, dot)
+			if err != nil {
+				return "", "", err
+			}
+			out.WriteString(value)
+		}
+	}
+	return out.String(), "", nil
+}
+
+// goreleaserBranch is a branch of an if or with block.
+type goreleaserBranch struct {
+	condition string // empty for else
+	body      string
+}
+
+// parseBlock converts an if or with block opened by action.
+func (c *goreleaserConverter) parseBlock(action, dot string) (string, error) {
+	keyword, condition, _ := strings.Cut(action, " ")
+	var branches []goreleaserBranch
+	for {
+		bodyDot := dot
+		if keyword == "with" {
+			bodyDot = condition
+		}
+		body, stop, err := c.parse(bodyDot)
+		if err != nil {
+			return "", err
+		}
+		branches = append(branches, goreleaserBranch{condition: condition, body: body})
+
+		switch {
+		case stop == "end":
+			return c.resolveBlock(keyword, branches)
+		case stop == "else":
+			keyword, condition = "else", ""
+		case strings.HasPrefix(stop, "else if "):
+			keyword, condition = "if", strings.TrimPrefix(stop, "else if ")
+		default:
+			return "", fmt.Errorf("unterminated {{ %s }} in goreleaser name template", action)
+		}
+	}
+}
+
+// resolveBlock turns the branches of a block into pattern text: ARM version suffixes become
+// {armversion}, chains comparing .Os or .Arch with literals become {os} or {arch} with the literal
+// mappings recorded as aliases, and micro-architecture blocks are dropped.
+func (c *goreleaserConverter) resolveBlock(keyword string, branches []goreleaserBranch) (string, error) {
+	first := branches[0]
+	for _, field := range goreleaserMicroarchFields {
+		if strings.Contains(first.condition, field) {
+			return "", nil
+		}
+	}
+	if (first.condition == ".Arm" || first.condition == "ne .Arm \"\"") && len(branches) == 1 {
+		if first.body != "v"+goreleaserArmMarker {
+			return "", fmt.Errorf("unsupported .Arm block in goreleaser name template; use {{ with .Arm }}v{{ . }}{{ end }}")
+		}
+		return "{armversion}", nil
+	}
+
+	// A chain such as {{ if eq .Arch "amd64" }}x86_64{{ else }}{{ .Arch }}{{ end }}
+	field, placeholder := "", ""
+	aliases := make(map[string][]string)
+	for i, branch := range branches {
+		if branch.condition == "" && i == len(branches)-1 && i > 0 {
+			if branch.body != placeholder {
+				return "", fmt.Errorf("unsupported else branch %q in goreleaser name template; it must be {{ .%s }}", branch.body, field)
+			}
+			if field == "Os" {
+				c.osAliases = mergeAliases(c.osAliases, aliases)
+			} else {
+				c.archAliases = mergeAliases(c.archAliases, aliases)
+			}
+			return placeholder, nil
+		}
+		m := goreleaserEqCondition.FindStringSubmatch(branch.condition)
+		if m == nil || (field != "" && m[1] != field) || strings.Contains(branch.body, "{") {
+			break
+		}
+		field, placeholder = m[1], "{"+strings.ToLower(m[1])+"}"
+		aliases[m[2]] = append(aliases[m[2]], branch.body)
+	}
+	return "", fmt.Errorf("unsupported {{ %s %s }} block in goreleaser name template", keyword, first.condition)
+}
+
+// eval converts a template pipeline into pattern text.
+func (c *goreleaserConverter) eval(pipeline, dot string) (string, error) {
+	// Normalize "x | f" to "f x"
+	if arg, fn, ok := strings.Cut(pipeline, " | "); ok {
+		pipeline = fn + " " + arg
+	}
+	if pipeline == "." && dot != "" {
+		pipeline = dot
+	}
+	for _, field := range goreleaserMicroarchFields {
+		if strings.Contains(pipeline, field) {
+			return "", nil // Within a block that is dropped, see resolveBlock
+		}
+	}
+
+	switch pipeline {
+	case ".ProjectName":
+		return c.projectName, nil
+	case ".Version", ".RawVersion":
+		return "{version_no_v}", nil
+	case ".Tag":
+		return "{version}", nil
+	case ".Os", "tolower .Os", "lower .Os":
+		return "{os}", nil
+	case "title .Os":
+		titles := make(map[string][]string)
+		for _, goos := range goreleaserTitleOS {
+			titles[goos] = []string{strings.ToUpper(goos[:1]) + goos[1:]}
+		}
+		c.osAliases = mergeAliases(c.osAliases, titles)
+		return "{os}", nil
+	case ".Arch", "tolower .Arch", "lower .Arch":
+		return "{arch}", nil
+	case ".Arm":
+		return goreleaserArmMarker, nil
+	}
+	return "", fmt.Errorf("unsupported {{ %s }} in goreleaser name template", pipeline)
+}
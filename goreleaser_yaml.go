@@ -0,0 +1,259 @@
+package ghupdate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document with its indentation.
+type yamlLine struct {
+	indent int
+	text   string // without indentation and trailing comment
+	raw    string // without indentation, for block scalars
+}
+
+// yamlParser parses the subset of YAML used by goreleaser configuration files: block mappings and
+// sequences, plain and quoted scalars, flow sequences of scalars and block scalars ("|", ">").
+// Anchors, aliases, multi-document streams and flow mappings are not supported. Mappings decode to
+// map[string]any, sequences to []any and scalars to string.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML parses a YAML document (see yamlParser).
+//
+// It returns an error if the document is malformed or uses unsupported syntax.
+func parseYAML(data []byte) (any, error) {
+	p := &yamlParser{}
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: tabs are not allowed for indentation")
+		}
+		p.lines = append(p.lines, yamlLine{
+			indent: len(line) - len(trimmed),
+			text:   stripYAMLComment(trimmed),
+			raw:    trimmed,
+		})
+	}
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return map[string]any{}, nil
+	}
+	if p.lines[p.pos].text == "---" {
+		p.pos++
+		p.skipBlank()
+	}
+	if p.pos == len(p.lines) {
+		return map[string]any{}, nil
+	}
+	node, err := p.parseNode(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml: line %d: unexpected indentation", p.pos+1)
+	}
+	return node, nil
+}
+
+// stripYAMLComment removes a trailing comment from a line, ignoring "#" within quoted strings.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return strings.TrimRight(line[:i], " ")
+		}
+	}
+	return strings.TrimRight(line, " ")
+}
+
+// skipBlank advances past empty and comment-only lines.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+// parseNode parses the mapping or sequence starting at the current line, indented by indent.
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	if isYAMLSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// isYAMLSequenceItem reports whether text starts a sequence item.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseSequence parses the items of a block sequence indented by indent.
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	var items []any
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || !isYAMLSequenceItem(line.text) {
+			return nil, fmt.Errorf("yaml: line %d: unexpected indentation", p.pos+1)
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			item, err := p.parseChild(indent, true)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+
+		// The item's content continues on the dash line, e.g. "- goos: windows"
+		offset := line.indent + len(line.text) - len(rest)
+		p.lines[p.pos] = yamlLine{indent: offset, text: rest, raw: line.raw[len(line.text)-len(rest):]}
+		if isYAMLSequenceItem(rest) || isYAMLMappingEntry(rest) {
+			item, err := p.parseNode(offset)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		value, err := parseYAMLScalar(rest)
+		if err != nil {
+			return nil, fmt.Errorf("yaml: line %d: %w", p.pos+1, err)
+		}
+		items = append(items, value)
+		p.pos++
+	}
+	return items, nil
+}
+
+// isYAMLMappingEntry reports whether text is a "key: value" or "key:" mapping entry.
+func isYAMLMappingEntry(text string) bool {
+	if text == "" || text[0] == '"' || text[0] == '\'' || text[0] == '[' || text[0] == '{' {
+		return false
+	}
+	return strings.Contains(text, ": ") || strings.HasSuffix(text, ":")
+}
+
+// parseMapping parses the entries of a block mapping indented by indent.
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	entries := make(map[string]any)
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || !isYAMLMappingEntry(line.text) {
+			return nil, fmt.Errorf("yaml: line %d: expected a mapping entry", p.pos+1)
+		}
+
+		key, value, _ := strings.Cut(line.text, ":")
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		value = strings.TrimSpace(value)
+		p.pos++
+
+		switch {
+		case value == "":
+			child, err := p.parseChild(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			entries[key] = child
+		case value[0] == '|' || value[0] == '>':
+			entries[key] = p.parseBlockScalar(indent, value[0] == '>')
+		default:
+			scalar, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, fmt.Errorf("yaml: line %d: %w", p.pos, err)
+			}
+			entries[key] = scalar
+		}
+	}
+	return entries, nil
+}
+
+// parseChild parses the value of a mapping entry or sequence item whose content starts on the next
+// line: a node indented deeper than indent, a sequence at the same indentation as a mapping key, or
+// nothing (an empty string).
+func (p *yamlParser) parseChild(indent int, inSequence bool) (any, error) {
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return "", nil
+	}
+	line := p.lines[p.pos]
+	if line.indent > indent || (!inSequence && line.indent == indent && isYAMLSequenceItem(line.text)) {
+		return p.parseNode(line.indent)
+	}
+	return "", nil
+}
+
+// parseBlockScalar parses the lines of a literal ("|") or folded (">") block scalar indented deeper
+// than indent. Folded lines are joined with spaces, literal ones with newlines; the result is
+// trimmed of surrounding whitespace.
+func (p *yamlParser) parseBlockScalar(indent int, folded bool) string {
+	var lines []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if line.raw != "" && line.indent <= indent {
+			break
+		}
+		lines = append(lines, line.raw)
+	}
+	sep := "\n"
+	if folded {
+		sep = " "
+	}
+	return strings.TrimSpace(strings.Join(lines, sep))
+}
+
+// parseYAMLScalar parses a plain or quoted scalar, or a flow sequence of scalars ("[a, b]").
+func parseYAMLScalar(value string) (any, error) {
+	switch value[0] {
+	case '"':
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double-quoted string %s", value)
+		}
+		return s, nil
+	case '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return nil, fmt.Errorf("invalid single-quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case '[':
+		if value[len(value)-1] != ']' {
+			return nil, fmt.Errorf("invalid flow sequence %s", value)
+		}
+		var items []any
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			scalar, err := parseYAMLScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, scalar)
+		}
+		return items, nil
+	case '{':
+		return nil, fmt.Errorf("flow mappings are not supported")
+	}
+	return value, nil
+}
@@ -0,0 +1,89 @@
+package ghupdate
+
+import (
+	"slices"
+	"time"
+)
+
+// maxUpdateHistory is the number of completed updates kept in the update history.
+const maxUpdateHistory = 20
+
+// UpdateRecord describes a completed update.
+type UpdateRecord struct {
+	// FromVersion is the version that was replaced.
+	FromVersion string `json:"from_version"`
+	// ToVersion is the version that was installed.
+	ToVersion string `json:"to_version"`
+	// AssetName is the name of the installed release asset, if known.
+	AssetName string `json:"asset_name,omitempty"`
+	// CompletedAt is the time the update was completed.
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// MarkUpdateCompleted records that the update described by info has been installed, for applications
+// that apply updates by other means than ApplyUpdate (e.g., exec or a systemd restart). The record is
+// appended to the update history and reported once by the next call to WasJustUpdated.
+// HandleUpdateMode and the in-process fallback of ApplyUpdate call it automatically.
+//
+// It returns an error if the updater state in dataDir cannot be read or written.
+func MarkUpdateCompleted(dataDir string, info *UpdateInfo) error {
+	record := UpdateRecord{
+		FromVersion: info.CurrentVersion,
+		ToVersion:   info.LatestVersion,
+		AssetName:   info.AssetName,
+		CompletedAt: time.Now().UTC(),
+	}
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.JustUpdated = &record
+		state.Failures = slices.DeleteFunc(state.Failures, func(f VersionFailures) bool { return f.Version == record.ToVersion })
+		state.History = append(state.History, record)
+		if len(state.History) > maxUpdateHistory {
+			state.History = state.History[len(state.History)-maxUpdateHistory:]
+		}
+	})
+}
+
+// WasJustUpdated reports whether an update was completed since the last call, consuming the record,
+// so applications can show a "what's new" message exactly once after an update.
+//
+// It returns the completed update, or nil if there was none, or an error if the updater state in
+// dataDir cannot be read or written.
+func WasJustUpdated(dataDir string) (*UpdateRecord, error) {
+	state, err := loadState(dataDir)
+	if err != nil || state.JustUpdated == nil {
+		return nil, err
+	}
+	record := state.JustUpdated
+	state.JustUpdated = nil
+	if err := saveState(UpdateConfig{DataDir: dataDir}, state); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// UpdateHistory returns the most recent completed updates recorded in dataDir, oldest first.
+func UpdateHistory(dataDir string) ([]UpdateRecord, error) {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return state.History, nil
+}
+
+// stagedVersion returns the version of the update most recently staged in dataDir, or an empty string.
+func stagedVersion(dataDir string) string {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return ""
+	}
+	return state.StagedVersion
+}
+
+// markStagedUpdateCompleted records the completion of the update staged in dataDir, which replaced
+// fromVersion. It is used by HandleUpdateMode and the in-process fallback of ApplyUpdate.
+func markStagedUpdateCompleted(dataDir, fromVersion string) error {
+	return MarkUpdateCompleted(dataDir, &UpdateInfo{
+		CurrentVersion: fromVersion,
+		LatestVersion:  stagedVersion(dataDir),
+	})
+}
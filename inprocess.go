@@ -0,0 +1,63 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+)
+
+// applyInProcess installs the staged update at updatePath over config.ExecutablePath from the
+// running process. It is used by ApplyUpdate when spawning the staged binary failed with spawnErr.
+//
+// It returns an error wrapping ErrUpdatedInProcess if the executable was replaced, or an error
+// describing both failures otherwise.
+func applyInProcess(config UpdateConfig, updatePath string, spawnErr error) error {
+	if err := installInProcess(config, updatePath); err != nil {
+		return fmt.Errorf("failed to start update process: %v; in-process replacement also failed: %w", spawnErr, err)
+	}
+	return fmt.Errorf("%w (failed to start update process: %v)", ErrUpdatedInProcess, spawnErr)
+}
+
+// installInProcess installs the staged update at updatePath over config.ExecutablePath from the
+// running process, together with the auxiliary files staged from its archive, and records the
+// completed update. Termination signals are deferred until the replacement is complete, and then
+// end the process (see exitOnDeferredSignal).
+//
+// It returns an error if the executable cannot be replaced.
+func installInProcess(config UpdateConfig, updatePath string) error {
+	defer exitOnDeferredSignal(deferSignals(nil))
+
+	if err := writeUpdateMarker(config, os.Getpid()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	defer removeUpdateMarker(config.MarkerPath)
+
+	archiveFiles, err := prepareArchiveFiles(config)
+	if err != nil {
+		return err
+	}
+	if err := backupExecutable(config, config.ExecutablePath, config.CurrentVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	err = retryWhileBusy(config.ReplaceRetryTimeout, func() error {
+		return replaceRunningExecutable(updatePath, config.ExecutablePath)
+	})
+	if err != nil {
+		abortArchiveFiles(archiveFiles)
+		return err
+	}
+	if err := applyOwnership(config, config.ExecutablePath); err != nil {
+		abortArchiveFiles(archiveFiles)
+		return err
+	}
+
+	if err := installArchiveFiles(config.DataDir, archiveFiles); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// The staged file has been installed and is no longer needed
+	os.Remove(updatePath)
+	if err := markStagedUpdateCompleted(config.DataDir, config.CurrentVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
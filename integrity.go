@@ -0,0 +1,86 @@
+package ghupdate
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrNoRecordedChecksum is returned by VerifyIntegrity when no checksum was recorded for CurrentVersion,
+	// e.g. because the version was installed by other means than ghupdate.
+	ErrNoRecordedChecksum = errors.New("no checksum recorded for the current version")
+	// ErrIntegrityMismatch is returned by VerifyIntegrity when the executable on disk does not match
+	// the checksum recorded when its version was staged.
+	ErrIntegrityMismatch = errors.New("executable does not match the recorded checksum")
+)
+
+// VerifyIntegrity hashes the installed executable and compares it against the SHA-256 checksum
+// recorded in DataDir when config.CurrentVersion was staged by ghupdate. This lets applications
+// detect on-disk tampering or a partially applied past update and trigger a repair reinstall.
+//
+// The executable checked is config.ExecutablePath, or the running executable if it is empty.
+//
+// It returns nil if the executable is intact, an error wrapping ErrNoRecordedChecksum if nothing
+// was recorded for CurrentVersion, an error wrapping ErrIntegrityMismatch if the hashes differ,
+// or another error if the executable or state cannot be read.
+func VerifyIntegrity(config UpdateConfig) error {
+	if config.CurrentVersion == "" || config.DataDir == "" {
+		return fmt.Errorf("invalid config: CurrentVersion and DataDir are required")
+	}
+
+	path := config.ExecutablePath
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to get current executable path: %w", err)
+		}
+		path = exe
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	state, err := loadState(config.DataDir)
+	if err != nil {
+		return err
+	}
+	expected, ok := state.Checksums[config.CurrentVersion]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoRecordedChecksum, config.CurrentVersion)
+	}
+
+	actual, err := hashFile(path, sha256.New())
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("%w: %s has SHA-256 %s, expected %s for %s", ErrIntegrityMismatch, path, actual, expected, config.CurrentVersion)
+	}
+	return nil
+}
+
+// recordStagedChecksum records the SHA-256 checksum of a staged executable for its version so that
+// VerifyIntegrity can check the installation once the update has been applied.
+// Checksums of versions older than the running one are dropped, as they can no longer be installed.
+func recordStagedChecksum(config UpdateConfig, path, version string) error {
+	sum, err := hashFile(path, sha256.New())
+	if err != nil {
+		return err
+	}
+
+	return updateState(config, func(state *updaterState) {
+		for v := range state.Checksums {
+			if v != config.CurrentVersion && compareVersions(config, v, config.CurrentVersion) < 0 {
+				delete(state.Checksums, v)
+			}
+		}
+		if state.Checksums == nil {
+			state.Checksums = make(map[string]string)
+		}
+		state.Checksums[version] = sum
+		state.StagedVersion = version
+	})
+}
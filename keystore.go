@@ -0,0 +1,47 @@
+package ghupdate
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrKeystoreUnavailable is returned when no supported OS keystore can be used on the current platform.
+var ErrKeystoreUnavailable = errors.New("OS keystore is not available on this platform")
+
+// OSKeystoreKey returns a key function that loads a 32-byte key from the operating system keystore,
+// generating and storing a new random key on first use.
+//
+// The key is stored under the given service and account names using:
+// - macOS: the login keychain, via the `security` tool.
+// - Linux: the Secret Service (GNOME Keyring, KWallet), via the `secret-tool` utility.
+// - Windows: a DPAPI-protected file in the user's config directory, bound to the current user.
+//
+// On other platforms, or when the required tooling is missing, the returned function
+// yields an error wrapping ErrKeystoreUnavailable.
+func OSKeystoreKey(service, account string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		secret, found, err := keystoreGet(service, account)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("failed to generate key: %w", err)
+			}
+			if err := keystoreSet(service, account, base64.StdEncoding.EncodeToString(key)); err != nil {
+				return nil, err
+			}
+			return key, nil
+		}
+
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key stored for %s/%s: %w", service, account, err)
+		}
+		return key, nil
+	}
+}
@@ -0,0 +1,52 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keystoreSupported reports whether OSKeystoreKey can use a keystore on this platform.
+const keystoreSupported = true
+
+// keystoreGet reads a secret from the macOS login keychain.
+// The `security` tool exits with status 44 when the item does not exist.
+func keystoreGet(service, account string) (string, bool, error) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return "", false, fmt.Errorf("%w: security tool not found", ErrKeystoreUnavailable)
+	}
+
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read keychain item %s/%s: %w", service, account, err)
+	}
+	return strings.TrimSpace(string(out)), true, nil
+}
+
+// keystoreSet stores a secret in the macOS login keychain, updating any existing item.
+// The command is fed to `security -i` on stdin rather than passed as arguments, so the secret
+// never appears in the process list. Interactive mode does not report failures in its exit
+// status, so the item is read back to confirm it was stored.
+func keystoreSet(service, account, secret string) error {
+	command := "add-generic-password -U -s " + securityQuote(service) + " -a " + securityQuote(account) + " -w " + securityQuote(secret) + "\n"
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to store keychain item %s/%s: %w: %s", service, account, err, strings.TrimSpace(string(out)))
+	}
+	if stored, found, err := keystoreGet(service, account); err != nil || !found || stored != secret {
+		return fmt.Errorf("failed to store keychain item %s/%s: %s", service, account, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityQuote quotes s as a single argument for the command parser of `security -i`.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,40 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keystoreSupported reports whether OSKeystoreKey can use a keystore on this platform.
+const keystoreSupported = true
+
+// keystoreGet reads a secret from the Secret Service using `secret-tool`.
+// `secret-tool lookup` exits with status 1 and no output when the item does not exist.
+func keystoreGet(service, account string) (string, bool, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", false, fmt.Errorf("%w: secret-tool not found", ErrKeystoreUnavailable)
+	}
+
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read secret %s/%s: %w", service, account, err)
+	}
+	return strings.TrimSpace(string(out)), true, nil
+}
+
+// keystoreSet stores a secret in the Secret Service using `secret-tool`.
+// The secret is passed on stdin so it never appears in the process list.
+func keystoreSet(service, account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+service+" "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store secret %s/%s: %w: %s", service, account, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
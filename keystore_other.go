@@ -0,0 +1,16 @@
+//go:build !darwin && !linux && !windows
+
+package ghupdate
+
+// keystoreSupported reports whether OSKeystoreKey can use a keystore on this platform.
+const keystoreSupported = false
+
+// keystoreGet reports that no keystore is supported on this platform.
+func keystoreGet(service, account string) (string, bool, error) {
+	return "", false, ErrKeystoreUnavailable
+}
+
+// keystoreSet reports that no keystore is supported on this platform.
+func keystoreSet(service, account, secret string) error {
+	return ErrKeystoreUnavailable
+}
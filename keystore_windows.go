@@ -0,0 +1,103 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// keystoreSupported reports whether OSKeystoreKey can use a keystore on this platform.
+const keystoreSupported = true
+
+var (
+	modCrypt32             = syscall.NewLazyDLL("crypt32.dll")
+	modKernel32            = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = modCrypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = modCrypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = modKernel32.NewProc("LocalFree")
+)
+
+// dataBlob mirrors the Win32 DATA_BLOB structure used by DPAPI.
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+// keystoreGet reads a DPAPI-protected secret stored in the user's config directory.
+func keystoreGet(service, account string) (string, bool, error) {
+	path, err := keystoreFile(service, account)
+	if err != nil {
+		return "", false, err
+	}
+
+	protected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read protected key %q: %w", path, err)
+	}
+
+	plain, err := dpapiCall(procCryptUnprotectData, protected)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to unprotect key %q: %w", path, err)
+	}
+	return string(plain), true, nil
+}
+
+// keystoreSet protects a secret with DPAPI for the current user and writes it to the user's config directory.
+func keystoreSet(service, account, secret string) error {
+	path, err := keystoreFile(service, account)
+	if err != nil {
+		return err
+	}
+
+	protected, err := dpapiCall(procCryptProtectData, []byte(secret))
+	if err != nil {
+		return fmt.Errorf("failed to protect key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory for %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, protected, 0600); err != nil {
+		return fmt.Errorf("failed to write protected key %q: %w", path, err)
+	}
+	return nil
+}
+
+// keystoreFile returns the location of the protected key file for a service and account.
+func keystoreFile(service, account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrKeystoreUnavailable, err)
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(account) + ".key"
+	return filepath.Join(dir, service, "keys", name), nil
+}
+
+// dpapiCall invokes CryptProtectData or CryptUnprotectData on the given input and returns the output blob.
+func dpapiCall(proc *syscall.LazyProc, input []byte) ([]byte, error) {
+	if err := proc.Find(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeystoreUnavailable, err)
+	}
+
+	var in dataBlob
+	if len(input) > 0 {
+		in = dataBlob{cbData: uint32(len(input)), pbData: &input[0]}
+	}
+	var out dataBlob
+
+	r, _, err := proc.Call(uintptr(unsafe.Pointer(&in)), 0, 0, 0, 0, 0, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+
+	result := make([]byte, out.cbData)
+	copy(result, unsafe.Slice(out.pbData, out.cbData))
+	return result, nil
+}
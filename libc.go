@@ -0,0 +1,80 @@
+package ghupdate
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// envLibc overrides the detected C library when UpdateConfig.Libc is empty.
+const envLibc = "GHUPDATE_LIBC"
+
+const (
+	// LibcGNU denotes the GNU C library, used by most Linux distributions.
+	LibcGNU = "gnu"
+	// LibcMusl denotes the musl C library, used by Alpine Linux among others.
+	LibcMusl = "musl"
+)
+
+// hostLibc caches the C library of the running system, which does not change while it runs.
+var hostLibc = sync.OnceValue(detectLibc)
+
+// resolveLibc determines the C library ("gnu" or "musl") of a Linux target. The Libc config field
+// takes precedence, followed by the GHUPDATE_LIBC environment variable, the C library of the running
+// system when the target is Linux and the application runs on Linux, and finally "gnu". It returns an
+// empty string for other operating systems.
+func resolveLibc(config UpdateConfig, targetOS string) string {
+	if targetOS != "linux" {
+		return ""
+	}
+	if libc := firstNonEmpty(config.Libc, os.Getenv(envLibc)); libc != "" {
+		return libc
+	}
+	if runtime.GOOS == "linux" {
+		if libc := hostLibc(); libc != "" {
+			return libc
+		}
+	}
+	return LibcGNU
+}
+
+// libcNames returns the {libc} values to try for the target. On glibc systems, assets without a
+// C library suffix are tried after glibc ones, as they are commonly the default glibc builds, and
+// musl builds last, as they are usually statically linked and run anywhere; the reverse is not
+// true. Only the exact C library is returned if fallbacks is false.
+func libcNames(config UpdateConfig, targetOS string, fallbacks bool) []string {
+	libc := resolveLibc(config, targetOS)
+	if libc == LibcGNU && fallbacks {
+		return []string{LibcGNU, "", LibcMusl}
+	}
+	return []string{libc}
+}
+
+// detectLibc returns the C library of the running system, judging by the dynamic loader of
+// /bin/sh, or by the presence of the musl loader if /bin/sh is statically linked or missing.
+// It returns an empty string if the C library cannot be determined.
+func detectLibc() string {
+	if f, err := elf.Open("/bin/sh"); err == nil {
+		defer f.Close()
+		for _, prog := range f.Progs {
+			if prog.Type != elf.PT_INTERP {
+				continue
+			}
+			data := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(data, 0); err != nil {
+				break
+			}
+			if strings.Contains(string(data), "musl") {
+				return LibcMusl
+			}
+			return LibcGNU
+		}
+	}
+	if loaders, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(loaders) > 0 {
+		return LibcMusl
+	}
+	return ""
+}
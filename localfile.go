@@ -0,0 +1,238 @@
+package ghupdate
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PrepareUpdateFromFile validates and stages an update provided as a local file, e.g. copied from a
+// USB drive in an air-gapped deployment, so it can be installed with ApplyUpdate like a downloaded
+// update. No network access is needed, and GitHubOwner, GitHubRepo and AssetPattern are not required.
+//
+// The file may be a signed bundle created by CreateBundle, which is verified and staged with
+// PrepareBundle; version may then be empty, and must otherwise match the bundle's version. Any other
+// file is treated as the executable of the given version, or as an archive holding it if its name
+// ends in an archive extension such as ".tar.gz" or ".zip" (see UpdateConfig.BinaryPathInArchive). It must be an executable for the target
+// platform, and if a checksum file named after it with a ".sha256" suffix (in sha256sum format) is
+// present, it must match. Unsigned executables are refused when ManifestPublicKey is set, since only
+// bundles carry a signature. Downgrade protection and the smoke test apply as for downloads.
+//
+// It returns nil if version is not newer than CurrentVersion, or an error if the file fails
+// validation or staging fails.
+func PrepareUpdateFromFile(config UpdateConfig, path, version string) (*UpdateInfo, error) {
+	if config.CurrentVersion == "" || config.DataDir == "" || config.ExecutablePath == "" {
+		return nil, fmt.Errorf("invalid config: CurrentVersion, DataDir and ExecutablePath are required")
+	}
+
+	if isBundle(path) {
+		info, err := PrepareBundle(config, path)
+		if err == nil && info != nil && version != "" && compareVersions(config, info.LatestVersion, version) != 0 {
+			CleanupUpdate(config.DataDir)
+			return nil, fmt.Errorf("bundle contains version %s, expected %s", info.LatestVersion, version)
+		}
+		return info, err
+	}
+
+	if len(config.ManifestPublicKey) > 0 {
+		return nil, fmt.Errorf("refusing unsigned executable %q: ManifestPublicKey requires a signed bundle", path)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("version is required for executable %q", path)
+	}
+	targetOS, _ := resolvePlatform(config)
+	format := archiveFormat(path)
+	if format == "" {
+		if err := checkExecutableFormat(path, targetOS); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isNewerVersion(config, config.CurrentVersion, version) {
+		return nil, nil // No update needed
+	}
+	if err := checkDowngrade(config, version); err != nil {
+		return nil, err
+	}
+
+	updatePath, err := chooseStagedUpdatePath(config)
+	if err != nil {
+		return nil, err
+	}
+	partialPath := partialDownloadPath(updatePath)
+	var sum string
+	if format == "" {
+		sum, err = copyAndHash(config, path, partialPath)
+	} else {
+		sum, err = extractLocalArchive(config, path, format, partialPath, targetOS, version)
+	}
+	if err != nil {
+		os.Remove(partialPath)
+		return nil, err
+	}
+
+	if expected, ok, err := readChecksumFile(path + ".sha256"); err != nil {
+		os.Remove(partialPath)
+		return nil, err
+	} else if ok && expected != sum {
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("checksum mismatch for %q: expected %s, got %s", path, expected, sum)
+	}
+
+	if err := os.Rename(partialPath, updatePath); err != nil {
+		os.Remove(partialPath)
+		return nil, fmt.Errorf("failed to move verified executable into place: %w", err)
+	}
+	if err := finalizeStagedUpdate(config, updatePath, version); err != nil {
+		return nil, err
+	}
+
+	return &UpdateInfo{
+		CurrentVersion: config.CurrentVersion,
+		LatestVersion:  version,
+		AssetName:      filepath.Base(path),
+		SHA256:         sum,
+	}, nil
+}
+
+// extractLocalArchive hashes the archive at path and extracts its executable to destPath (see
+// extractArchiveBinary), checking that it is an executable for targetOS.
+//
+// It returns the hex-encoded SHA-256 of the archive.
+func extractLocalArchive(config UpdateConfig, path, format, destPath, targetOS, version string) (string, error) {
+	sum, err := hashFile(path, sha256.New())
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), dirMode(config)); err != nil {
+		return "", fmt.Errorf("failed to create directory for %q: %w", destPath, err)
+	}
+	if err := extractArchiveBinary(config, path, format, destPath, version); err != nil {
+		return "", fmt.Errorf("failed to extract executable from %q: %w", path, err)
+	}
+	if err := checkExecutableFormat(destPath, targetOS); err != nil {
+		return "", err
+	}
+	if err := stageArchiveFiles(config, path, format, version); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// isBundle reports whether the file at path is a tar archive starting with a bundle manifest.
+func isBundle(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header, err := tar.NewReader(f).Next()
+	return err == nil && header.Name == bundleManifestName
+}
+
+// executableMagic lists the leading bytes of executables, by target operating system.
+var executableMagic = map[string][][]byte{
+	"windows": {[]byte("MZ")},
+	"darwin": {
+		{0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 64-bit
+		{0xce, 0xfa, 0xed, 0xfe}, // Mach-O 32-bit
+		{0xca, 0xfe, 0xba, 0xbe}, // universal binary
+	},
+}
+
+// checkExecutableFormat checks that the file at path starts like an executable for targetOS:
+// a PE image on Windows, a Mach-O image on macOS and an ELF image elsewhere. Scripts starting with
+// "#!" are accepted on all platforms but Windows.
+//
+// It returns an error if the file cannot be read or is not an executable for targetOS.
+func checkExecutableFormat(path, targetOS string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	head := make([]byte, 4)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	magics, ok := executableMagic[targetOS]
+	if !ok {
+		magics = [][]byte{[]byte("\x7fELF")}
+	}
+	if targetOS != "windows" {
+		magics = append(magics, []byte("#!"))
+	}
+	for _, magic := range magics {
+		if bytes.HasPrefix(head, magic) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not an executable for %s", path, targetOS)
+}
+
+// copyAndHash copies the file at src to dst with the configured FileMode, hashing it on the way.
+//
+// It returns the hex-encoded SHA-256 of the copied content.
+func copyAndHash(config UpdateConfig, src, dst string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file %q: %w", src, err)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), dirMode(config)); err != nil {
+		return "", fmt.Errorf("failed to create directory for %q: %w", dst, err)
+	}
+	out, err := openStagedFile(config, dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file %q: %w", dst, err)
+	}
+	defer out.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), in); err != nil {
+		return "", fmt.Errorf("failed to copy content from %q to %q: %w", src, dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %q: %w", dst, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readChecksumFile reads a SHA-256 checksum file in sha256sum format ("<hex>  <name>"), of which
+// only the hash of the first line is used.
+//
+// It returns false if the file does not exist, or an error if it cannot be read or is malformed.
+func readChecksumFile(path string) (string, bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open checksum file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(f, 4096)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false, fmt.Errorf("failed to read checksum file %q: %w", path, err)
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false, fmt.Errorf("checksum file %q is empty", path)
+	}
+	sum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", false, fmt.Errorf("checksum file %q does not hold a SHA-256 hash", path)
+	}
+	return sum, true, nil
+}
@@ -0,0 +1,45 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrLocked is returned when a lock file is held by another running process.
+var ErrLocked = errors.New("lock is held by another process")
+
+// acquireLock creates an exclusive lock file at path with perm containing the current PID.
+// A lock left behind by a process that is no longer running is considered stale and taken over.
+//
+// It returns a function that releases the lock, or an error wrapping ErrLocked if another live
+// process holds it.
+func acquireLock(path string, perm os.FileMode) (func(), error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %q: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read lock file %q: %w", path, err)
+		}
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid != os.Getpid() && isProcessRunning(pid) {
+			return nil, fmt.Errorf("%w: %s (PID %d)", ErrLocked, path, pid)
+		}
+
+		// The owner is gone; remove the stale lock and try again.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale lock file %q: %w", path, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+}
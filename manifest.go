@@ -0,0 +1,258 @@
+package ghupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultManifestAssetName is the release asset name used for signed manifests when
+	// UpdateConfig.ManifestAssetName is empty. The signature is published as the same name plus ".sig".
+	DefaultManifestAssetName = "ghupdate-manifest.json"
+	// maxManifestSize caps the size of manifest and signature assets downloaded into memory.
+	maxManifestSize = 1 << 20
+)
+
+// Manifest is a publisher-signed list of the assets of a release.
+// It is generated in CI with GenerateManifest and SignManifest (or the ghupdate-sign command),
+// uploaded next to the release assets, and verified by CheckAndPrepareUpdate when
+// UpdateConfig.ManifestPublicKey is set.
+type Manifest struct {
+	// Version is the release tag the manifest was generated for.
+	Version string `json:"version"`
+	// Assets lists the name, size and hash of every signed asset.
+	Assets []ManifestAsset `json:"assets"`
+}
+
+// ManifestAsset describes a single asset in a Manifest.
+type ManifestAsset struct {
+	// Name is the asset file name as uploaded to the release.
+	Name string `json:"name"`
+	// Size is the asset size in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded SHA-256 hash of the asset.
+	SHA256 string `json:"sha256"`
+}
+
+// Asset returns the manifest entry for the named asset, if present.
+func (m *Manifest) Asset(name string) (*ManifestAsset, bool) {
+	for i := range m.Assets {
+		if m.Assets[i].Name == name {
+			return &m.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// GenerateManifest builds a manifest for a release version from the files at the given paths.
+// Each asset is named after the base name of its path.
+//
+// It returns an error if any file cannot be read.
+func GenerateManifest(version string, paths ...string) (*Manifest, error) {
+	manifest := &Manifest{Version: version}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %q: %w", path, err)
+		}
+		sum, err := hashFile(path, sha256.New())
+		if err != nil {
+			return nil, err
+		}
+		manifest.Assets = append(manifest.Assets, ManifestAsset{
+			Name:   filepath.Base(path),
+			Size:   info.Size(),
+			SHA256: sum,
+		})
+	}
+	return manifest, nil
+}
+
+// SignManifest encodes a manifest and signs the encoded bytes with an Ed25519 private key.
+// The returned signature is base64-encoded so it can be uploaded as a text asset.
+//
+// It returns the encoded manifest and its signature, or an error if the key is invalid.
+func SignManifest(manifest *Manifest, key ed25519.PrivateKey) ([]byte, []byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, nil, fmt.Errorf("invalid Ed25519 private key length %d", len(key))
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return data, []byte(sig), nil
+}
+
+// VerifyManifest checks a base64-encoded Ed25519 signature over the manifest bytes and decodes them.
+//
+// It returns the decoded manifest, or an error if the signature is invalid or the manifest is malformed.
+func VerifyManifest(data, sig []byte, key ed25519.PublicKey) (*Manifest, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key length %d", len(key))
+	}
+	rawSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode manifest signature: %w", err)
+	}
+	if !ed25519.Verify(key, data, rawSig) {
+		return nil, fmt.Errorf("manifest signature verification failed")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// DecodePublicKey decodes a base64-encoded Ed25519 public key, as written by `ghupdate-sign keygen`.
+func DecodePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key length %d", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// DecodePrivateKey decodes a base64-encoded Ed25519 private key, as written by `ghupdate-sign keygen`.
+func DecodePrivateKey(s string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key length %d", len(key))
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// fetchReleaseManifest downloads the manifest and signature assets of a release and verifies them
+// with config.ManifestPublicKey. The manifest must have been generated for the release's tag.
+func fetchReleaseManifest(ctx context.Context, config UpdateConfig, release *GitHubRelease) (*Manifest, error) {
+	name := config.ManifestAssetName
+	if name == "" {
+		name = DefaultManifestAssetName
+	}
+
+	var manifestAsset, sigAsset *GitHubAsset
+	for i := range release.Assets {
+		switch release.Assets[i].Name {
+		case name:
+			manifestAsset = &release.Assets[i]
+		case name + ".sig":
+			sigAsset = &release.Assets[i]
+		}
+	}
+	if manifestAsset == nil || sigAsset == nil {
+		return nil, fmt.Errorf("release %s has no signed manifest (%s and %s.sig)", release.TagName, name, name)
+	}
+
+	var data, sig []byte
+	err := runConcurrently(ctx,
+		func(ctx context.Context) (err error) {
+			data, err = fetchReleaseAssetBytes(ctx, config, manifestAsset)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			sig, err = fetchReleaseAssetBytes(ctx, config, sigAsset)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := VerifyManifest(data, sig, config.ManifestPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Version != release.TagName {
+		return nil, fmt.Errorf("manifest was signed for version %s, but release is %s", manifest.Version, release.TagName)
+	}
+	return manifest, nil
+}
+
+// verifyManifestAsset checks the file at path against the manifest entry for the named asset.
+// knownSHA256 is the SHA-256 of the file if already computed, or empty (see verifyAssetDigest).
+func verifyManifestAsset(manifest *Manifest, name, path, knownSHA256 string) error {
+	entry, ok := manifest.Asset(name)
+	if !ok {
+		return fmt.Errorf("asset %s is not listed in the signed manifest", name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %q: %w", path, err)
+	}
+	if info.Size() != entry.Size {
+		return fmt.Errorf("size of %s does not match signed manifest: expected %d bytes, got %d", name, entry.Size, info.Size())
+	}
+	return verifyAssetDigest(path, "sha256:"+entry.SHA256, knownSHA256)
+}
+
+// fetchReleaseAssetBytes downloads a small release asset into memory, falling back from the browser URL
+// to the API endpoint and retrying transient failures like downloadReleaseAsset.
+func fetchReleaseAssetBytes(ctx context.Context, config UpdateConfig, asset *GitHubAsset) ([]byte, error) {
+	var err error
+	for _, url := range assetDownloadURLs(config, asset) {
+		var data []byte
+		err = withRetry(config, func() error {
+			var fetchErr error
+			data, fetchErr = fetchAssetBytes(ctx, config, url)
+			return fetchErr
+		})
+		var status *statusError
+		if !errors.As(err, &status) || status.StatusCode != http.StatusNotFound {
+			return data, err
+		}
+	}
+	return nil, err
+}
+
+// fetchAssetBytes downloads a small release asset into memory.
+func fetchAssetBytes(ctx context.Context, config UpdateConfig, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request for %q: %w", url, err)
+	}
+	setUserAgent(config, req)
+	if err := setAuthHeader(config, req); err != nil {
+		return nil, err
+	}
+	setAssetAccept(req)
+
+	client := httpClient(config, checkTimeout(config))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(resp.Body, maxManifestSize+1)); err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", url, err)
+	}
+	if buf.Len() > maxManifestSize {
+		return nil, fmt.Errorf("asset at %q exceeds %d bytes", url, maxManifestSize)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UpdateMarker is the content of the JSON marker file written to UpdateConfig.MarkerPath while an
+// update is being applied. External tooling (cron jobs, configuration management, monitoring) can
+// treat the presence of the file as "do not touch the binary".
+type UpdateMarker struct {
+	// Executable is the path of the executable being replaced.
+	Executable string `json:"executable"`
+	// FromVersion is the version being replaced.
+	FromVersion string `json:"from_version,omitempty"`
+	// PID is the ID of the process performing the replacement.
+	PID int `json:"pid"`
+	// StartedAt is when the update started being applied.
+	StartedAt time.Time `json:"started_at"`
+}
+
+// writeUpdateMarker writes the update marker for config, naming pid as the process applying the update.
+// It does nothing if no MarkerPath is configured.
+func writeUpdateMarker(config UpdateConfig, pid int) error {
+	if config.MarkerPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(UpdateMarker{
+		Executable:  config.ExecutablePath,
+		FromVersion: config.CurrentVersion,
+		PID:         pid,
+		StartedAt:   time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode update marker: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.MarkerPath), dirMode(config)); err != nil {
+		return fmt.Errorf("failed to create directory for update marker %q: %w", config.MarkerPath, err)
+	}
+	tmp := config.MarkerPath + ".tmp"
+	if err := os.WriteFile(tmp, data, fileMode(config)&^0111); err != nil {
+		return fmt.Errorf("failed to write update marker %q: %w", config.MarkerPath, err)
+	}
+	if err := os.Rename(tmp, config.MarkerPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write update marker %q: %w", config.MarkerPath, err)
+	}
+	return nil
+}
+
+// removeUpdateMarker removes the update marker at path, if any.
+func removeUpdateMarker(path string) {
+	if path != "" {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,81 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+)
+
+// ScheduleUpdateOnNextLaunch marks the update prepared by CheckAndPrepareUpdate to be installed the
+// next time the application starts, for long-running daemons and GUI applications that cannot
+// restart right after the download. The application then calls ApplyPendingUpdate early in main.
+//
+// It returns an error if no update is prepared or the updater state cannot be written.
+func ScheduleUpdateOnNextLaunch(config UpdateConfig) error {
+	if config.DataDir == "" {
+		return fmt.Errorf("invalid config: DataDir is required")
+	}
+	version := stagedVersion(config.DataDir)
+	if version == "" || !hasPreparedUpdate(config) {
+		return fmt.Errorf("no prepared update found in %s", config.DataDir)
+	}
+	return updateState(config, func(state *updaterState) {
+		state.ApplyOnLaunch = version
+	})
+}
+
+// hasPreparedUpdate reports whether a staged update exists, in plain or encrypted form.
+func hasPreparedUpdate(config UpdateConfig) bool {
+	path := preparedUpdatePath(config.DataDir)
+	if config.EncryptStaging {
+		path = encryptedUpdatePath(config.DataDir)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ApplyPendingUpdate installs the update scheduled with ScheduleUpdateOnNextLaunch, if any. It is
+// meant to be called early in main, after HandleUpdateMode and before CleanupUpdate, which removes
+// the staged update: the executable is replaced from the
+// running process and then started again with the same arguments, environment and working
+// directory (see UpdateModeOptions.Relaunch), so the swap is transparent to the user. On Unix the
+// process keeps its PID.
+//
+// It returns false if no update is pending, including when the staged update has since been
+// replaced by another version or removed. It returns true and an error wrapping
+// ErrUpdatedInProcess if the executable was replaced but could not be started again, in which case
+// the application is still running the old version and should ask to be restarted. Any other error
+// means the update could not be installed; the schedule is cleared either way, so that a broken
+// update does not fail every launch.
+func ApplyPendingUpdate(config UpdateConfig) (bool, error) {
+	if config.DataDir == "" || config.ExecutablePath == "" {
+		return false, fmt.Errorf("invalid config: DataDir and ExecutablePath are required")
+	}
+	state, err := loadState(config.DataDir)
+	if err != nil {
+		return false, err
+	}
+	version := state.ApplyOnLaunch
+	if version == "" {
+		return false, nil
+	}
+	if err := updateState(config, func(state *updaterState) { state.ApplyOnLaunch = "" }); err != nil {
+		return false, err
+	}
+	if version != state.StagedVersion || !hasPreparedUpdate(config) {
+		return false, nil
+	}
+
+	updatePath := preparedUpdatePath(config.DataDir)
+	if config.EncryptStaging {
+		if err := decryptStagedUpdate(config, updatePath); err != nil {
+			return false, err
+		}
+	}
+	if err := installInProcess(config, updatePath); err != nil {
+		recordFailure(config, version, err)
+		return false, fmt.Errorf("failed to install pending update %s: %w", version, err)
+	}
+
+	err = relaunch(config.ExecutablePath, os.Args)
+	return true, fmt.Errorf("%w (failed to start %s: %v)", ErrUpdatedInProcess, version, err)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package ghupdate
+
+// isNoexecDir reports whether files in dir cannot be executed.
+// Non-Unix platforms have no noexec mounts, so it always returns false.
+func isNoexecDir(dir string) bool {
+	return false
+}
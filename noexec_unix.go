@@ -0,0 +1,29 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os"
+	"syscall"
+)
+
+// isNoexecDir reports whether files in dir cannot be executed, typically because the file system
+// is mounted noexec. It probes by creating an executable temporary file and checking it with access(2),
+// which fails with EACCES on noexec mounts.
+// It returns false if the probe cannot be performed.
+func isNoexecDir(dir string) bool {
+	f, err := os.CreateTemp(dir, ".ghupdate-exec-probe-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	f.Close()
+	defer os.Remove(name)
+
+	if err := os.Chmod(name, 0700); err != nil {
+		return false
+	}
+
+	const xOK = 0x1 // X_OK
+	return syscall.Access(name, xOK) != nil
+}
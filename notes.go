@@ -0,0 +1,104 @@
+package ghupdate
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxReleaseNotesSize caps the size of a release notes asset used for UpdateInfo.ReleaseNotes.
+	maxReleaseNotesSize = 256 << 10
+	// maxReleaseNotesPages caps the number of release pages scanned for intermediate release notes.
+	maxReleaseNotesPages = 5
+)
+
+// DefaultReleaseNotesAssets are the asset name patterns recognized as release notes when
+// UpdateConfig.ReleaseNotesAssets is nil. Matching is case-insensitive.
+var DefaultReleaseNotesAssets = []string{"changelog.md", "changelog.txt", "release-notes.md", "release-notes.txt", "release_notes.md", "release_notes.txt"}
+
+// releaseNotes returns the notes of release for UpdateInfo: the content of the first attached asset
+// matching config.ReleaseNotesAssets (or DefaultReleaseNotesAssets) if it is valid UTF-8 text of at
+// most 256 KiB, and the release body otherwise. Failures to fetch the asset fall back to the body.
+func releaseNotes(config UpdateConfig, release *GitHubRelease) string {
+	patterns := config.ReleaseNotesAssets
+	if patterns == nil {
+		patterns = DefaultReleaseNotesAssets
+	}
+
+	for _, pattern := range patterns {
+		for i := range release.Assets {
+			asset := &release.Assets[i]
+			if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(asset.Name)); !matched {
+				continue
+			}
+			if asset.Size > maxReleaseNotesSize {
+				return release.Body
+			}
+			data, err := fetchReleaseAssetBytes(context.Background(), config, asset)
+			if err != nil || len(data) > maxReleaseNotesSize || !utf8.Valid(data) {
+				return release.Body
+			}
+			return string(data)
+		}
+	}
+	return release.Body
+}
+
+// updateReleaseNotes returns the notes shown for an update to release. With
+// config.AggregateReleaseNotes and an upgrade, the notes of every release of the followed channel
+// after CurrentVersion up to release are concatenated, newest first, each under a "## <tag>"
+// heading; intermediate releases contribute their body. If the releases cannot be listed, or there
+// are no intermediate releases, only the notes of release are returned (see releaseNotes).
+func updateReleaseNotes(config UpdateConfig, release *GitHubRelease) string {
+	notes := releaseNotes(config, release)
+	if !config.AggregateReleaseNotes || config.RedirectMode || compareVersions(config, release.TagName, config.CurrentVersion) <= 0 {
+		return notes
+	}
+
+	intermediate, err := intermediateReleases(config, release)
+	if err != nil || len(intermediate) == 0 {
+		return notes
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n%s", release.TagName, strings.TrimSpace(notes))
+	for _, r := range intermediate {
+		fmt.Fprintf(&b, "\n\n## %s\n\n%s", r.TagName, strings.TrimSpace(r.Body))
+	}
+	return b.String()
+}
+
+// intermediateReleases lists the releases of the followed channel that are newer than
+// CurrentVersion and older than release, newest first. At most maxReleaseNotesPages pages are scanned.
+//
+// It returns an error if the releases cannot be fetched.
+func intermediateReleases(config UpdateConfig, release *GitHubRelease) ([]GitHubRelease, error) {
+	channel := effectiveChannel(config)
+	var found []GitHubRelease
+	for page := 1; page <= maxReleaseNotesPages; page++ {
+		releases, err := ListReleases(config, ListOptions{Page: page, PerPage: maxReleasesPerPage})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range releases {
+			if !config.IncludePrereleases && !inChannel(&r, channel) {
+				continue
+			}
+			if compareVersions(config, r.TagName, config.CurrentVersion) > 0 && compareVersions(config, r.TagName, release.TagName) < 0 {
+				found = append(found, r)
+			}
+		}
+		if len(releases) < maxReleasesPerPage {
+			break
+		}
+	}
+
+	slices.SortStableFunc(found, func(a, b GitHubRelease) int {
+		return compareVersions(config, b.TagName, a.TagName)
+	})
+	return found, nil
+}
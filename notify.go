@@ -0,0 +1,68 @@
+package ghupdate
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Notification is a native desktop notification.
+type Notification struct {
+	// Title is the notification title, typically the application name.
+	Title string
+	// Message is the notification body.
+	Message string
+	// OnClick is called when the user clicks the notification. Click-through is supported on Windows
+	// and on Linux desktops whose notify-send supports actions; it is never called on macOS.
+	OnClick func()
+}
+
+// Notify shows a native desktop notification without cgo: a balloon tip via PowerShell on Windows,
+// osascript on macOS and notify-send elsewhere. It returns as soon as the notification is shown;
+// OnClick is invoked from a separate goroutine.
+//
+// It returns an error if the notification tool is missing or fails to start.
+func Notify(n Notification) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		script := `Add-Type -AssemblyName System.Windows.Forms; Add-Type -AssemblyName System.Drawing;` +
+			`$n = New-Object Windows.Forms.NotifyIcon; $n.Icon = [Drawing.SystemIcons]::Information; $n.Visible = $true;` +
+			`$script:clicked = $false; $n.add_BalloonTipClicked({ $script:clicked = $true });` +
+			`$n.ShowBalloonTip(10000, $env:GHUPDATE_TITLE, $env:GHUPDATE_MESSAGE, 'Info');` +
+			`$end = (Get-Date).AddSeconds(15); while (-not $script:clicked -and (Get-Date) -lt $end) {` +
+			` [Windows.Forms.Application]::DoEvents(); Start-Sleep -Milliseconds 100 };` +
+			`$n.Dispose(); if ($script:clicked) { Write-Output 'default' }`
+		cmd = powerShellCommand(script, n.Title, n.Message)
+	case "darwin":
+		script := `display notification ` + appleScriptQuote(n.Message) + ` with title ` + appleScriptQuote(n.Title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		if n.OnClick != nil && notifySendSupportsActions() {
+			cmd = exec.Command("notify-send", "--app-name="+n.Title, "--action=default=Open", "--wait", n.Title, n.Message)
+		} else {
+			cmd = exec.Command("notify-send", "--app-name="+n.Title, n.Title, n.Message)
+		}
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	go func() {
+		if err := cmd.Wait(); err == nil && n.OnClick != nil && strings.TrimSpace(out.String()) == "default" {
+			n.OnClick()
+		}
+	}()
+	return nil
+}
+
+// notifySendSupportsActions reports whether the installed notify-send accepts --action and --wait
+// (libnotify 0.7.10 and later).
+func notifySendSupportsActions() bool {
+	help, err := exec.Command("notify-send", "--help").Output()
+	return err == nil && bytes.Contains(help, []byte("--action")) && bytes.Contains(help, []byte("--wait"))
+}
@@ -0,0 +1,13 @@
+package ghupdate
+
+import "syscall"
+
+// osVersion returns the macOS product version, e.g. "14.4.1".
+// It returns false if it cannot be determined.
+func osVersion() (string, bool) {
+	version, err := syscall.Sysctl("kern.osproductversion")
+	if err != nil || version == "" {
+		return "", false
+	}
+	return version, true
+}
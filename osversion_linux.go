@@ -0,0 +1,17 @@
+package ghupdate
+
+import (
+	"os"
+	"strings"
+)
+
+// osVersion returns the release of the running Linux kernel, e.g. "6.1.0-13-amd64".
+// It returns false if it cannot be determined.
+func osVersion() (string, bool) {
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return "", false
+	}
+	release := strings.TrimSpace(string(data))
+	return release, release != ""
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package ghupdate
+
+// osVersion reports that the operating system version cannot be determined on this platform.
+func osVersion() (string, bool) {
+	return "", false
+}
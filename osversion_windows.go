@@ -0,0 +1,33 @@
+package ghupdate
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var procRtlGetVersion = syscall.NewLazyDLL("ntdll.dll").NewProc("RtlGetVersion")
+
+// osVersionInfo mirrors the Windows RTL_OSVERSIONINFOW structure.
+type osVersionInfo struct {
+	size         uint32
+	majorVersion uint32
+	minorVersion uint32
+	buildNumber  uint32
+	platformID   uint32
+	csdVersion   [128]uint16
+}
+
+// osVersion returns the Windows version as major.minor.build, e.g. "10.0.19045". Unlike
+// GetVersionEx, RtlGetVersion reports the real version regardless of the application manifest.
+// It returns false if it cannot be determined.
+func osVersion() (string, bool) {
+	if procRtlGetVersion.Find() != nil {
+		return "", false
+	}
+	info := osVersionInfo{size: uint32(unsafe.Sizeof(osVersionInfo{}))}
+	if status, _, _ := procRtlGetVersion.Call(uintptr(unsafe.Pointer(&info))); status != 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%d.%d.%d", info.majorVersion, info.minorVersion, info.buildNumber), true
+}
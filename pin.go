@@ -0,0 +1,30 @@
+package ghupdate
+
+// Pin pins the installation in dataDir to version: subsequent calls to CheckAndPrepareUpdate stage
+// exactly that release (see UpdateToVersion) instead of the newest one, and report no update once
+// it is running. The pin is kept until Unpin is called.
+//
+// It returns an error if the updater state in dataDir cannot be read or written.
+func Pin(dataDir, version string) error {
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.PinnedVersion = version
+	})
+}
+
+// Unpin removes the pin set with Pin, so updates follow the channel again.
+//
+// It returns an error if the updater state in dataDir cannot be read or written.
+func Unpin(dataDir string) error {
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.PinnedVersion = ""
+	})
+}
+
+// PinnedVersion returns the version the installation in dataDir is pinned to, or an empty string.
+func PinnedVersion(dataDir string) (string, error) {
+	state, err := loadState(dataDir)
+	if err != nil {
+		return "", err
+	}
+	return state.PinnedVersion, nil
+}
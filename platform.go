@@ -0,0 +1,124 @@
+package ghupdate
+
+import (
+	"os"
+	"runtime"
+)
+
+const (
+	// envOS overrides the detected operating system when UpdateConfig.OS is empty.
+	envOS = "GHUPDATE_OS"
+	// envArch overrides the detected architecture when UpdateConfig.Arch is empty.
+	envArch = "GHUPDATE_ARCH"
+)
+
+// osAssetAliases lists alternative {os} values tried, in order, when no asset is published
+// under a platform's own GOOS name. illumos can run Solaris binaries, which are commonly
+// published under "solaris" only, and Android (Termux) runs static Linux binaries.
+var osAssetAliases = map[string][]string{
+	"illumos": {"solaris"},
+	"android": {"linux"},
+}
+
+// osNameAliases lists common spellings of GOOS values in asset names, such as uname-style or
+// marketing names. They are tried after the GOOS name itself when the release's asset list is known.
+var osNameAliases = map[string][]string{
+	"darwin":  {"macos", "macOS", "Darwin", "osx", "mac"},
+	"linux":   {"Linux"},
+	"windows": {"Windows", "win"},
+	"freebsd": {"FreeBSD"},
+	"openbsd": {"OpenBSD"},
+	"netbsd":  {"NetBSD"},
+}
+
+// platformNameAliases lists {os} spellings that also imply the architecture, such as "win64".
+var platformNameAliases = map[string][]string{
+	"windows/amd64": {"win64"},
+	"windows/386":   {"win32"},
+}
+
+// archNameAliases lists common spellings of GOARCH values in asset names, such as those reported
+// by uname -m. They are tried after the GOARCH name itself when the release's asset list is known.
+var archNameAliases = map[string][]string{
+	"amd64":   {"x86_64", "x64", "x86-64", "64bit"},
+	"386":     {"i386", "i686", "32bit"},
+	"arm64":   {"aarch64"},
+	"ppc64":   {"powerpc64"},
+	"ppc64le": {"powerpc64le"},
+	"loong64": {"loongarch64"},
+}
+
+// universalArchNames lists {arch} spellings of macOS universal binaries, which hold code for both
+// amd64 and arm64 (see lipo(1)).
+var universalArchNames = []string{"universal", "universal2", "all"}
+
+// assetOSNames returns the {os} values to try for a target operating system, starting with the OS itself.
+func assetOSNames(targetOS string) []string {
+	return append([]string{targetOS}, osAssetAliases[targetOS]...)
+}
+
+// assetPlatformNames returns the {os} and {arch} values to try for the target platform, in order:
+// the GOOS and GOARCH names, the aliases configured in OSAliases and ArchAliases and, if spellings
+// is set, the built-in spellings (see osNameAliases and archNameAliases). Compatible operating
+// systems (see osAssetAliases) follow the target OS with their own aliases. Built-in spellings are
+// left out when assets are probed (RedirectMode, TagsFallback), which costs a request per name.
+//
+// For macOS targets, the spellings of universal binaries (see universalArchNames) come last, or
+// first if config.PreferUniversal is set; they are only probed if it is set.
+func assetPlatformNames(config UpdateConfig, targetOS, targetArch string, spellings bool) ([]string, []string) {
+	var osNames []string
+	for _, base := range assetOSNames(targetOS) {
+		osNames = append(osNames, base)
+		osNames = append(osNames, config.OSAliases[base]...)
+		if spellings {
+			osNames = append(osNames, osNameAliases[base]...)
+			osNames = append(osNames, platformNameAliases[base+"/"+targetArch]...)
+		}
+	}
+
+	archNames := append([]string{targetArch}, config.ArchAliases[targetArch]...)
+	if spellings {
+		archNames = append(archNames, archNameAliases[targetArch]...)
+	}
+	if targetOS == "darwin" && (targetArch == "amd64" || targetArch == "arm64") {
+		if config.PreferUniversal {
+			archNames = append(append([]string{}, universalArchNames...), archNames...)
+		} else if spellings {
+			archNames = append(archNames, universalArchNames...)
+		}
+	}
+	return uniqueStrings(osNames), uniqueStrings(archNames)
+}
+
+// uniqueStrings returns values without duplicates, keeping the first occurrence of each.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// resolvePlatform determines the target operating system and architecture for asset selection.
+// Explicit config fields take precedence, followed by the GHUPDATE_OS and GHUPDATE_ARCH environment
+// variables, which let users running under emulation (Rosetta, qemu-user, WOW64) force the intended
+// asset, and finally the platform the binary was compiled for.
+func resolvePlatform(config UpdateConfig) (string, string) {
+	targetOS := firstNonEmpty(config.OS, os.Getenv(envOS), runtime.GOOS)
+	targetArch := firstNonEmpty(config.Arch, os.Getenv(envArch), runtime.GOARCH)
+	return targetOS, targetArch
+}
+
+// firstNonEmpty returns the first non-empty string among values.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
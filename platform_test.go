@@ -0,0 +1,38 @@
+package ghupdate
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAssetOSNames(t *testing.T) {
+	tests := []struct {
+		targetOS string
+		want     []string
+	}{
+		{"linux", []string{"linux"}},
+		{"freebsd", []string{"freebsd"}},
+		{"openbsd", []string{"openbsd"}},
+		{"netbsd", []string{"netbsd"}},
+		{"illumos", []string{"illumos", "solaris"}},
+		{"solaris", []string{"solaris"}},
+		{"android", []string{"android", "linux"}},
+	}
+	for _, tt := range tests {
+		if got := assetOSNames(tt.targetOS); !slices.Equal(got, tt.want) {
+			t.Errorf("assetOSNames(%q) = %q, want %q", tt.targetOS, got, tt.want)
+		}
+	}
+}
+
+func TestAssetPlatformNamesBSDSpellings(t *testing.T) {
+	for targetOS, spelling := range map[string]string{"freebsd": "FreeBSD", "openbsd": "OpenBSD", "netbsd": "NetBSD"} {
+		osNames, _ := assetPlatformNames(UpdateConfig{}, targetOS, "amd64", true)
+		if !slices.Contains(osNames, spelling) {
+			t.Errorf("assetPlatformNames(%q) = %q, want it to include %q", targetOS, osNames, spelling)
+		}
+		if osNames, _ := assetPlatformNames(UpdateConfig{}, targetOS, "amd64", false); slices.Contains(osNames, spelling) {
+			t.Errorf("assetPlatformNames(%q) without spellings = %q, want no %q", targetOS, osNames, spelling)
+		}
+	}
+}
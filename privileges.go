@@ -0,0 +1,188 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// downloadModeFlag is the argument used to launch the application as an unprivileged download helper.
+const downloadModeFlag = "--ghupdate-download"
+
+// Ownership identifies the user and group that should own files written by the updater.
+type Ownership struct {
+	// UID is the numeric user ID of the owner.
+	UID int
+	// GID is the numeric group ID of the owner.
+	GID int
+}
+
+// String formats the ownership as "uid:gid".
+func (o Ownership) String() string {
+	return strconv.Itoa(o.UID) + ":" + strconv.Itoa(o.GID)
+}
+
+// parseOwnership parses an ownership in the "uid:gid" form produced by Ownership.String.
+func parseOwnership(s string) (*Ownership, error) {
+	uid, gid, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid ownership %q", s)
+	}
+	u, err := strconv.Atoi(uid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid in ownership %q: %w", s, err)
+	}
+	g, err := strconv.Atoi(gid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid in ownership %q: %w", s, err)
+	}
+	return &Ownership{UID: u, GID: g}, nil
+}
+
+// downloadRequest is the job handed to an unprivileged download helper on its standard input.
+type downloadRequest struct {
+	URL      string      `json:"url"`
+	DestPath string      `json:"dest_path"`
+	Size     int64       `json:"size,omitempty"`
+	Resume   bool        `json:"resume,omitempty"`
+	Token    string      `json:"token,omitempty"`
+	Scheme   AuthScheme  `json:"scheme,omitempty"`
+	FileMode os.FileMode `json:"file_mode,omitempty"`
+	DirMode  os.FileMode `json:"dir_mode,omitempty"`
+	// Connections and ChunkedThreshold carry the chunked download settings.
+	Connections      int   `json:"connections,omitempty"`
+	ChunkedThreshold int64 `json:"chunked_threshold,omitempty"`
+	// Timeout carries the download timeout.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Proxy carries the explicit proxy URL, including any credentials.
+	Proxy string `json:"proxy,omitempty"`
+	// UserAgent carries the resolved User-Agent header.
+	UserAgent string `json:"user_agent,omitempty"`
+	// Strict carries StrictTransport.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// runningAsRoot reports whether the current process has an effective user ID of 0.
+// It is always false on Windows, where os.Geteuid returns -1.
+func runningAsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// downloadAssetAs downloads url to destPath, performing the network phase in a child process
+// running as config.Owner when DropPrivileges is enabled and the updater runs as root.
+// Otherwise it downloads directly in the current process.
+//
+// The child is the application itself, launched with the download helper flag, so HandleUpdateMode
+// must be called at the start of main for privilege dropping to work.
+//
+// It returns the SHA-256 computed while downloading in the current process (see downloadAsset), or an
+// empty string when the child downloaded the file.
+func downloadAssetAs(config UpdateConfig, url, destPath string, expectedSize int64, resumable bool) (string, error) {
+	if !config.DropPrivileges || config.Owner == nil || !runningAsRoot() {
+		return downloadAsset(config, url, destPath, expectedSize, resumable)
+	}
+
+	// The unprivileged helper must be able to write into the staging directory.
+	dir := filepath.Dir(destPath)
+	if err := os.MkdirAll(dir, dirMode(config)); err != nil {
+		return "", fmt.Errorf("failed to create directory for %q: %w", destPath, err)
+	}
+	if err := chownPath(dir, config.Owner); err != nil {
+		return "", err
+	}
+
+	token, err := resolveToken(config)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := json.Marshal(downloadRequest{
+		URL:      url,
+		DestPath: destPath,
+		Size:     expectedSize,
+		Resume:   resumable,
+		Token:    token.Value,
+		Scheme:   token.Scheme,
+		FileMode: config.FileMode,
+		DirMode:  config.DirMode,
+
+		Connections:      config.DownloadConnections,
+		ChunkedThreshold: config.ChunkedThreshold,
+		Timeout:          config.DownloadTimeout,
+		Proxy:            config.ProxyURL,
+		UserAgent:        userAgent(config),
+		Strict:           config.StrictTransport,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode download request: %w", err)
+	}
+
+	cmd := exec.Command(config.ExecutablePath, downloadModeFlag)
+	// The request, including any token, is passed on stdin to keep it out of argv and the environment.
+	cmd.Stdin = strings.NewReader(string(request))
+	cmd.Stderr = os.Stderr
+	if err := setCredential(cmd, config.Owner); err != nil {
+		return "", err
+	}
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("unprivileged download helper failed: %w", err)
+	}
+	return "", nil
+}
+
+// runDownloadMode executes a download job read from stdin and exits the process.
+// It is invoked by HandleUpdateMode when the application is launched with the download helper flag.
+func runDownloadMode() {
+	var request downloadRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid download request: %v\n", err)
+		os.Exit(1)
+	}
+
+	token := Token{Value: request.Token, Scheme: request.Scheme}
+	config := UpdateConfig{
+		TokenSource: TokenSourceFunc(func() (Token, error) { return token, nil }),
+		FileMode:    request.FileMode,
+		DirMode:     request.DirMode,
+
+		DownloadConnections: request.Connections,
+		ChunkedThreshold:    request.ChunkedThreshold,
+		DownloadTimeout:     request.Timeout,
+		ProxyURL:            request.Proxy,
+		UserAgent:           request.UserAgent,
+		StrictTransport:     request.Strict,
+	}
+	if _, err := downloadAsset(config, request.URL, request.DestPath, request.Size, request.Resume); err != nil {
+		fmt.Fprintf(os.Stderr, "Download failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// applyOwnership changes the owner of the given paths to config.Owner.
+// It does nothing when no owner is configured or the updater is not running as root.
+func applyOwnership(config UpdateConfig, paths ...string) error {
+	if config.Owner == nil || !runningAsRoot() {
+		return nil
+	}
+	for _, path := range paths {
+		if err := chownPath(path, config.Owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chownPath changes the owner of path to owner.
+func chownPath(path string, owner *Ownership) error {
+	if err := os.Chown(path, owner.UID, owner.GID); err != nil {
+		return fmt.Errorf("failed to change owner of %q to %s: %w", path, owner, err)
+	}
+	return nil
+}
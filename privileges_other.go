@@ -0,0 +1,14 @@
+//go:build !unix
+
+package ghupdate
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// setCredential reports that dropping privileges is not supported on this platform.
+func setCredential(cmd *exec.Cmd, owner *Ownership) error {
+	return fmt.Errorf("dropping privileges is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCredential configures cmd to run as owner, clearing supplementary groups.
+func setCredential(cmd *exec.Cmd, owner *Ownership) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid:    uint32(owner.UID),
+			Gid:    uint32(owner.GID),
+			Groups: []uint32{},
+		},
+	}
+	return nil
+}
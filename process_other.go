@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package ghupdate
+
+// processCheckSupported reports whether isProcessRunning can tell whether a process is running.
+const processCheckSupported = false
+
+// isProcessRunning reports false on platforms without a supported process existence check,
+// so waiting for the previous process falls through immediately.
+func isProcessRunning(pid int) bool {
+	return false
+}
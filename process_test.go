@@ -0,0 +1,62 @@
+package ghupdate
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// helperProcessEnv makes the test binary act as a long-running process (see TestHelperProcess).
+const helperProcessEnv = "GHUPDATE_TEST_HELPER_PROCESS"
+
+// TestHelperProcess is not a real test: run with helperProcessEnv set, it sleeps so that other
+// tests have a live process, running from a known executable, to observe.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(helperProcessEnv) != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// startHelperProcess runs the executable at path as a helper process (see TestHelperProcess),
+// killing it when the test ends.
+func startHelperProcess(t *testing.T, path string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(path, "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperProcessEnv+"=1")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start helper process: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd
+}
+
+func TestIsProcessRunning(t *testing.T) {
+	if !processCheckSupported {
+		t.Skip("no process existence check on this platform")
+	}
+	if !isProcessRunning(os.Getpid()) {
+		t.Errorf("isProcessRunning(%d) = false for the test process", os.Getpid())
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := startHelperProcess(t, exe)
+	pid := cmd.Process.Pid
+	if !isProcessRunning(pid) {
+		t.Errorf("isProcessRunning(%d) = false for a running child", pid)
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	if isProcessRunning(pid) {
+		t.Errorf("isProcessRunning(%d) = true for an exited child", pid)
+	}
+}
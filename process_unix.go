@@ -0,0 +1,50 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// processCheckSupported reports whether isProcessRunning can tell whether a process is running.
+const processCheckSupported = true
+
+// isProcessRunning checks if a process with the given PID is currently running.
+// It sends signal 0, which performs error checking without delivering a signal. This works on
+// Linux, macOS, the BSDs, illumos and Solaris alike.
+// EPERM means the process exists but belongs to another user, so it is reported as running.
+// Under Android/Termux, where signalling may be denied by SELinux policy, /proc is consulted instead.
+//
+// It returns true if the process is running, false otherwise.
+func isProcessRunning(pid int) bool {
+	if isTermux() {
+		return isProcRunning(pid)
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false // Process not found or error accessing it
+	}
+
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// isProcRunning checks for a live process through /proc, treating zombies as exited.
+func isProcRunning(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+
+	// The state field follows the parenthesized command name, which may itself contain spaces.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 || end+2 >= len(stat) {
+		return true
+	}
+	return stat[end+2] != 'Z'
+}
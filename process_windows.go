@@ -0,0 +1,46 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// processCheckSupported reports whether isProcessRunning can tell whether a process is running.
+const processCheckSupported = true
+
+// isProcessRunning checks if a process with the given PID is currently running.
+// On Windows, os.FindProcess can succeed for processes that have already exited,
+// so the check is confirmed with isWindowsProcessRunning.
+//
+// It returns true if the process is running, false otherwise.
+func isProcessRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false // Process not found or error accessing it
+	}
+	process.Release()
+
+	return isWindowsProcessRunning(pid)
+}
+
+// isWindowsProcessRunning specifically checks if a Windows process with the given PID is running.
+// It executes the `tasklist` command and parses its output to determine process existence.
+//
+// It returns true if the Windows process is running, false otherwise.
+func isWindowsProcessRunning(pid int) bool {
+	// Use tasklist command to check if process exists
+	// /FI "PID eq %d" filters by PID, /FO CSV formats the output as CSV.
+	cmd := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH") // /NH for No Header
+	output, err := cmd.Output()
+	if err != nil {
+		return false // Command failed or process not found
+	}
+
+	// If the process exists, tasklist will return a line with process info.
+	// If not found, it returns only "No tasks are running for the specified criteria."
+	// or similar, or just headers if /NH is not used.
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return len(lines) > 0 && !strings.Contains(lines[0], "No tasks are running")
+}
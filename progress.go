@@ -0,0 +1,36 @@
+package ghupdate
+
+import "sync"
+
+// progressTracker reports download progress to UpdateConfig.Progress. It is an io.Writer that counts
+// the bytes written through it, and serializes calls made from concurrent chunk downloads.
+// A nil tracker counts nothing.
+type progressTracker struct {
+	mu    sync.Mutex
+	fn    func(downloaded, total int64)
+	done  int64
+	total int64
+}
+
+// newProgressTracker returns a tracker for a download of total bytes of which start bytes are
+// already present, or nil if no Progress callback is configured.
+func newProgressTracker(config UpdateConfig, total, start int64) *progressTracker {
+	if config.Progress == nil {
+		return nil
+	}
+	p := &progressTracker{fn: config.Progress, done: start, total: total}
+	p.fn(p.done, p.total)
+	return p
+}
+
+// Write counts len(b) bytes as downloaded.
+func (p *progressTracker) Write(b []byte) (int, error) {
+	if p == nil {
+		return len(b), nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += int64(len(b))
+	p.fn(p.done, p.total)
+	return len(b), nil
+}
@@ -0,0 +1,154 @@
+package ghupdate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// fetchLatestReleaseByRedirect resolves the latest release without the GitHub API, for RedirectMode.
+// The tag is learned from the redirect of https://github.com/{owner}/{repo}/releases/latest, and the
+// assets are found by probing the download URLs of the names AssetPattern expands to for the target
+// platform, along with the signed manifest if one is required. The returned release has no notes,
+// and its assets carry no digest.
+//
+// It returns an error if the repository has no release, AssetPattern contains wildcards (which cannot
+// be resolved without the asset list), or a request fails.
+func fetchLatestReleaseByRedirect(config UpdateConfig) (*GitHubRelease, error) {
+	var tag string
+	err := withRetry(config, func() error {
+		var err error
+		tag, err = resolveLatestTag(config)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return probeRelease(config, tag, githubDownloadURLTemplate)
+}
+
+// githubDownloadURLTemplate is the conventional download URL of GitHub release assets.
+const githubDownloadURLTemplate = "https://github.com/{owner}/{repo}/releases/download/{tag}/{asset}"
+
+// probeRelease builds the release for tag without the GitHub API by probing the download URL
+// produced by urlTemplate (see expandDownloadURL) for each asset name AssetPattern expands to on the
+// target platform, without built-in spellings (see assetNames), and for the signed manifest if one
+// is required. Assets that do not exist are left out.
+//
+// It returns an error if AssetPattern is empty or contains wildcards, or a probe fails.
+func probeRelease(config UpdateConfig, tag, urlTemplate string) (*GitHubRelease, error) {
+	if config.AssetPattern == "" {
+		return nil, fmt.Errorf("AssetPattern is required to probe release assets")
+	}
+	if strings.ContainsAny(config.AssetPattern, "*?[") {
+		return nil, fmt.Errorf("asset pattern %q contains wildcards, which cannot be resolved without the releases API", config.AssetPattern)
+	}
+
+	targetOS, targetArch := resolvePlatform(config)
+	names := assetNames(config, tag, targetOS, targetArch, false)
+	if len(config.ManifestPublicKey) > 0 {
+		name := config.ManifestAssetName
+		if name == "" {
+			name = DefaultManifestAssetName
+		}
+		names = append(names, name, name+".sig")
+	}
+
+	release := &GitHubRelease{TagName: tag}
+	for _, name := range names {
+		downloadURL := expandDownloadURL(config, urlTemplate, tag, name)
+		var size int64
+		var found bool
+		err := withRetry(config, func() error {
+			var err error
+			size, found, err = probeReleaseAsset(config, downloadURL)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			release.Assets = append(release.Assets, GitHubAsset{Name: name, BrowserDownloadURL: downloadURL, Size: size})
+		}
+	}
+	return release, nil
+}
+
+// expandDownloadURL replaces the {owner}, {repo}, {tag} and {asset} placeholders of a download URL
+// template, escaping the tag and asset name as path segments.
+func expandDownloadURL(config UpdateConfig, template, tag, asset string) string {
+	return strings.NewReplacer(
+		"{owner}", config.GitHubOwner,
+		"{repo}", config.GitHubRepo,
+		"{tag}", url.PathEscape(tag),
+		"{asset}", url.PathEscape(asset),
+	).Replace(template)
+}
+
+// resolveLatestTag requests the latest release page without following its redirect and extracts
+// the tag from the redirect target, https://github.com/{owner}/{repo}/releases/tag/{tag}.
+func resolveLatestTag(config UpdateConfig) (string, error) {
+	latestURL := fmt.Sprintf("https://github.com/%s/%s/releases/latest", config.GitHubOwner, config.GitHubRepo)
+	req, err := http.NewRequest("HEAD", latestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request for %q: %w", latestURL, err)
+	}
+	setUserAgent(config, req)
+
+	client := httpClient(config, checkTimeout(config))
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve latest release: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode > 399 {
+		return "", &statusError{URL: latestURL, StatusCode: resp.StatusCode}
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("latest release redirect has no location: %w", err)
+	}
+	dir, tag := path.Split(location.Path)
+	if !strings.HasSuffix(dir, "/releases/tag/") || tag == "" {
+		return "", fmt.Errorf("repository %s/%s has no latest release", config.GitHubOwner, config.GitHubRepo)
+	}
+	if len(tag) > maxTagNameLength {
+		return "", fmt.Errorf("latest release tag exceeds the limit of %d bytes", maxTagNameLength)
+	}
+	return tag, nil
+}
+
+// probeReleaseAsset checks whether a release asset exists with a HEAD request to its download URL.
+// Existing assets redirect to a signed storage URL, which is not followed since it only accepts GET.
+//
+// It returns the asset size if the server reports one (0 otherwise), false if the asset does not
+// exist, or an error if the request fails.
+func probeReleaseAsset(config UpdateConfig, downloadURL string) (int64, bool, error) {
+	req, err := http.NewRequest("HEAD", downloadURL, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create HTTP request for %q: %w", downloadURL, err)
+	}
+	setUserAgent(config, req)
+
+	client := httpClient(config, checkTimeout(config))
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to probe %q: %w", downloadURL, err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, false, nil
+	case resp.StatusCode >= 300 && resp.StatusCode <= 399:
+		return 0, true, nil
+	case resp.StatusCode != http.StatusOK:
+		return 0, false, &statusError{URL: downloadURL, StatusCode: resp.StatusCode}
+	}
+	return max(resp.ContentLength, 0), true, nil
+}
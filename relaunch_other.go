@@ -0,0 +1,30 @@
+//go:build !unix
+
+package ghupdate
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// relaunch runs the executable at path with argv and the current environment, working directory
+// and standard streams, then exits with its exit code. It only returns if the executable cannot be
+// started.
+func relaunch(path string, argv []string) error {
+	cmd := exec.Command(path, argv[1:]...)
+	cmd.Args[0] = argv[0]
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		os.Exit(1)
+	}
+	os.Exit(0)
+	return nil // Never reached
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os"
+	"syscall"
+)
+
+// relaunch replaces the running process with the executable at path, started with argv and the
+// current environment. It only returns on failure.
+func relaunch(path string, argv []string) error {
+	return syscall.Exec(path, argv, os.Environ())
+}
@@ -0,0 +1,96 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// maxReleaseResponseSize caps the size of a release API response read into memory.
+	maxReleaseResponseSize = 10 << 20
+	// maxReleaseAssets caps the number of assets accepted per release; GitHub allows at most 1000.
+	maxReleaseAssets = 1000
+	// maxTagNameLength caps the length of a release tag name.
+	maxTagNameLength = 256
+)
+
+// decodeRelease decodes and validates a single release from a GitHub API response body.
+// The body is read up to maxReleaseResponseSize bytes.
+//
+// It returns an error if the body is too large, is not valid JSON, or fails validateRelease.
+func decodeRelease(r io.Reader) (*GitHubRelease, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxReleaseResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub release JSON: %w", err)
+	}
+	if len(data) > maxReleaseResponseSize {
+		return nil, fmt.Errorf("GitHub release JSON exceeds %d bytes", maxReleaseResponseSize)
+	}
+
+	var release *GitHubRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub release JSON: %w", err)
+	}
+	if release == nil {
+		return nil, fmt.Errorf("GitHub release JSON is null")
+	}
+	if err := validateRelease(release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// validateRelease checks that a decoded release has the fields the updater relies on. JSON nulls
+// decode to zero values, so missing and null fields are both reported here rather than surfacing
+// later as confusing asset matching or download errors.
+//
+// It returns an error describing the first problem found.
+func validateRelease(release *GitHubRelease) error {
+	if release.TagName == "" {
+		return fmt.Errorf("invalid GitHub release: tag_name is missing or empty")
+	}
+	if len(release.TagName) > maxTagNameLength {
+		return fmt.Errorf("invalid GitHub release: tag_name exceeds %d characters", maxTagNameLength)
+	}
+	if len(release.Assets) > maxReleaseAssets {
+		return fmt.Errorf("invalid GitHub release %s: %d assets exceed the limit of %d", release.TagName, len(release.Assets), maxReleaseAssets)
+	}
+
+	for i, asset := range release.Assets {
+		switch {
+		case asset.Name == "":
+			return fmt.Errorf("invalid GitHub release %s: asset %d has no name", release.TagName, i)
+		case asset.BrowserDownloadURL == "" && asset.URL == "":
+			return fmt.Errorf("invalid GitHub release %s: asset %s has no download URL", release.TagName, asset.Name)
+		case asset.Size < 0:
+			return fmt.Errorf("invalid GitHub release %s: asset %s has negative size %d", release.TagName, asset.Name, asset.Size)
+		}
+	}
+	return nil
+}
+
+// decodeReleases decodes and validates a list of releases from a GitHub API response body.
+// Draft releases are dropped, since they are not meant to be installed, unless includeDrafts is set.
+//
+// It returns an error if the body is not a valid JSON array or any release fails validateRelease.
+func decodeReleases(data []byte, includeDrafts bool) ([]GitHubRelease, error) {
+	var releases []*GitHubRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub releases JSON: %w", err)
+	}
+
+	var published []GitHubRelease
+	for i, release := range releases {
+		if release == nil {
+			return nil, fmt.Errorf("GitHub releases JSON has a null entry at index %d", i)
+		}
+		if err := validateRelease(release); err != nil {
+			return nil, err
+		}
+		if !release.Draft || includeDrafts {
+			published = append(published, *release)
+		}
+	}
+	return published, nil
+}
@@ -0,0 +1,93 @@
+package ghupdate
+
+import (
+	"bytes"
+	"testing"
+)
+
+// releaseSeeds are release documents covering valid, incomplete, null and malformed input.
+var releaseSeeds = []string{
+	`{"tag_name":"v1.2.3","assets":[{"name":"app-linux-amd64","browser_download_url":"https://example.com/app","size":10}]}`,
+	`{"tag_name":"v1.2.3","draft":true,"assets":[]}`,
+	`{"tag_name":"v1.2.3","assets":[{"name":"app","url":"https://api.example.com/assets/1","size":-1}]}`,
+	`{"tag_name":"v1.2.3","assets":[{"name":"","browser_download_url":"https://example.com/app"}]}`,
+	`{"tag_name":"v1.2.3","assets":[null]}`,
+	`{"tag_name":null,"assets":null}`,
+	`{"tag_name":""}`,
+	`{}`,
+	`null`,
+	`[]`,
+	`{"tag_name":"v1`,
+	``,
+}
+
+func FuzzDecodeRelease(f *testing.F) {
+	for _, seed := range releaseSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		release, err := decodeRelease(bytes.NewReader(data))
+		if err != nil {
+			if release != nil {
+				t.Fatalf("decodeRelease returned a release along with error %v", err)
+			}
+			return
+		}
+		if release == nil {
+			t.Fatal("decodeRelease returned neither a release nor an error")
+		}
+		if err := validateRelease(release); err != nil {
+			t.Fatalf("decodeRelease returned a release that fails validation: %v", err)
+		}
+	})
+}
+
+func FuzzDecodeReleases(f *testing.F) {
+	for _, seed := range releaseSeeds {
+		f.Add([]byte("["+seed+"]"), false)
+		f.Add([]byte("["+seed+","+releaseSeeds[1]+"]"), true)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, includeDrafts bool) {
+		releases, err := decodeReleases(data, includeDrafts)
+		if err != nil {
+			if releases != nil {
+				t.Fatalf("decodeReleases returned releases along with error %v", err)
+			}
+			return
+		}
+		for i := range releases {
+			if err := validateRelease(&releases[i]); err != nil {
+				t.Fatalf("decodeReleases returned release %d that fails validation: %v", i, err)
+			}
+			if releases[i].Draft && !includeDrafts {
+				t.Fatalf("decodeReleases returned draft release %s", releases[i].TagName)
+			}
+		}
+	})
+}
+
+func TestDecodeRelease(t *testing.T) {
+	release, err := decodeRelease(bytes.NewReader([]byte(releaseSeeds[0])))
+	if err != nil {
+		t.Fatalf("decodeRelease: %v", err)
+	}
+	if release.TagName != "v1.2.3" || len(release.Assets) != 1 || release.Assets[0].Size != 10 {
+		t.Errorf("decodeRelease = %+v", release)
+	}
+
+	for _, seed := range releaseSeeds[2:] {
+		if _, err := decodeRelease(bytes.NewReader([]byte(seed))); err == nil {
+			t.Errorf("decodeRelease(%s) succeeded, want an error", seed)
+		}
+	}
+}
+
+func TestDecodeReleasesDropsDrafts(t *testing.T) {
+	data := []byte("[" + releaseSeeds[0] + "," + releaseSeeds[1] + "]")
+	if releases, err := decodeReleases(data, false); err != nil || len(releases) != 1 {
+		t.Errorf("decodeReleases without drafts = %d releases (%v), want 1", len(releases), err)
+	}
+	if releases, err := decodeReleases(data, true); err != nil || len(releases) != 2 {
+		t.Errorf("decodeReleases with drafts = %d releases (%v), want 2", len(releases), err)
+	}
+}
@@ -0,0 +1,98 @@
+package ghupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// DefaultReleaseMetadataAssetName is the release asset declaring which machines a release supports
+// (see ReleaseMetadata).
+const DefaultReleaseMetadataAssetName = "ghupdate.json"
+
+// ErrUnsupportedPlatform is returned when a release's metadata excludes the target platform or
+// requires a newer operating system than the one running.
+var ErrUnsupportedPlatform = errors.New("release does not support this platform")
+
+// ReleaseMetadata declares which machines a release can be installed on. It is read from a
+// DefaultReleaseMetadataAssetName asset attached to the release, e.g.:
+//
+//	{
+//	  "min_os_version": {"darwin": "13.0", "windows": "10.0.17763"},
+//	  "excluded_platforms": ["windows/386", "freebsd/*"]
+//	}
+type ReleaseMetadata struct {
+	// MinOSVersion maps GOOS values to the minimum operating system version the release supports:
+	// the product version on macOS (e.g., "13.0"), the major.minor.build version on Windows
+	// (e.g., "10.0.17763") and the kernel release on Linux (e.g., "5.10"). Versions are compared
+	// component by component. The requirement is ignored if the version cannot be determined.
+	MinOSVersion map[string]string `json:"min_os_version,omitempty"`
+	// ExcludedPlatforms lists path.Match patterns of "os/arch" platforms the release must not be
+	// installed on, e.g. "windows/386" or "freebsd/*".
+	ExcludedPlatforms []string `json:"excluded_platforms,omitempty"`
+}
+
+// fetchReleaseMetadata fetches the DefaultReleaseMetadataAssetName asset of release.
+//
+// It returns nil if the release has no such asset, or an error if it cannot be fetched or decoded.
+func fetchReleaseMetadata(config UpdateConfig, release *GitHubRelease) (*ReleaseMetadata, error) {
+	matches := matchAssets(release.Assets, DefaultReleaseMetadataAssetName)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	data, err := fetchReleaseAssetBytes(context.Background(), config, &matches[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata of release %s: %w", release.TagName, err)
+	}
+
+	var metadata ReleaseMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of release %s: %w", release.TagName, err)
+	}
+	return &metadata, nil
+}
+
+// checkReleaseMetadata checks that release supports the target platform according to its metadata.
+// The minimum OS version is only checked when the target is the running operating system.
+//
+// It returns an error wrapping ErrUnsupportedPlatform if it does not, or an error if the metadata
+// cannot be fetched.
+func checkReleaseMetadata(config UpdateConfig, release *GitHubRelease) error {
+	metadata, err := fetchReleaseMetadata(config, release)
+	if err != nil || metadata == nil {
+		return err
+	}
+
+	targetOS, targetArch := resolvePlatform(config)
+	platform := targetOS + "/" + targetArch
+	for _, pattern := range metadata.ExcludedPlatforms {
+		if matched, _ := path.Match(pattern, platform); matched || pattern == targetOS {
+			return fmt.Errorf("%w: release %s excludes %s", ErrUnsupportedPlatform, release.TagName, platform)
+		}
+	}
+
+	minVersion := metadata.MinOSVersion[targetOS]
+	if minVersion == "" || targetOS != runtime.GOOS {
+		return nil
+	}
+	current, ok := osVersion()
+	if !ok {
+		return nil
+	}
+	if compareDotted(leadingVersion(current), leadingVersion(minVersion)) < 0 {
+		return fmt.Errorf("%w: release %s requires %s %s or later, running %s", ErrUnsupportedPlatform, release.TagName, targetOS, minVersion, current)
+	}
+	return nil
+}
+
+// leadingVersion returns the leading dotted numeric part of version, e.g. "6.1.0" of "6.1.0-13-amd64".
+func leadingVersion(version string) string {
+	if end := strings.IndexFunc(version, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); end >= 0 {
+		version = version[:end]
+	}
+	return strings.TrimSuffix(version, ".")
+}
@@ -0,0 +1,108 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxReleasesPerPage is the largest page size supported by the GitHub releases API.
+const maxReleasesPerPage = 100
+
+// ListOptions selects a page of releases for ListReleases.
+type ListOptions struct {
+	// Page is the 1-based page number. If zero, the first page is returned.
+	Page int
+	// PerPage is the number of releases per page, at most 100. If zero, 30 releases are returned.
+	PerPage int
+	// Channel restricts the releases to those belonging to a channel (see SwitchChannel):
+	// ChannelStable lists stable releases only. If empty, all published releases are listed.
+	Channel string
+}
+
+// ListReleases returns a page of the repository's published releases, newest first as ordered by
+// GitHub, with their tag, notes, assets, publication date and prerelease flag. It is meant for
+// applications offering their own "choose a version to install" UI; a chosen release is installed
+// with UpdateToVersion. Draft releases are only listed with UpdateConfig.IncludeDrafts. A page with fewer than PerPage releases
+// is the last one.
+//
+// It returns an error if the configuration is invalid, the options are out of range, RedirectMode
+// is enabled, or the releases cannot be fetched.
+func ListReleases(config UpdateConfig, opts ListOptions) ([]GitHubRelease, error) {
+	if err := validateReleaseConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.Page < 0 {
+		return nil, fmt.Errorf("invalid page %d", opts.Page)
+	}
+	if opts.PerPage < 0 || opts.PerPage > maxReleasesPerPage {
+		return nil, fmt.Errorf("invalid page size %d: must be between 1 and %d", opts.PerPage, maxReleasesPerPage)
+	}
+	if config.RedirectMode {
+		return nil, fmt.Errorf("listing releases is not supported in redirect mode")
+	}
+	if opts.Page == 0 {
+		opts.Page = 1
+	}
+	if opts.PerPage == 0 {
+		opts.PerPage = 30
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=%d&page=%d", config.GitHubOwner, config.GitHubRepo, opts.PerPage, opts.Page)
+	var releases []GitHubRelease
+	if err := fetchAPI(config, url, func(body []byte) error {
+		var err error
+		releases, err = decodeReleases(body, config.IncludeDrafts)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	if opts.Channel == "" {
+		return releases, nil
+	}
+	var listed []GitHubRelease
+	for i := range releases {
+		if inChannel(&releases[i], opts.Channel) {
+			listed = append(listed, releases[i])
+		}
+	}
+	return listed, nil
+}
+
+// newestEligibleRelease finds the newest release of the followed channel that is newer than
+// CurrentVersion and accepted by eligible, scanning at most maxReleaseNotesPages pages of releases.
+//
+// It returns nil if there is none, or an error if the releases cannot be listed.
+func newestEligibleRelease(config UpdateConfig, eligible func(*GitHubRelease) bool) (*GitHubRelease, error) {
+	channel := effectiveChannel(config)
+	var newest *GitHubRelease
+	for page := 1; page <= maxReleaseNotesPages; page++ {
+		releases, err := ListReleases(config, ListOptions{Page: page, PerPage: maxReleasesPerPage})
+		if err != nil {
+			return nil, err
+		}
+		for i := range releases {
+			r := &releases[i]
+			if !config.IncludePrereleases && !inChannel(r, channel) {
+				continue
+			}
+			if !isNewerVersion(config, config.CurrentVersion, r.TagName) || !eligible(r) {
+				continue
+			}
+			if newest == nil || compareVersions(config, r.TagName, newest.TagName) > 0 {
+				newest = r
+			}
+		}
+		if len(releases) < maxReleasesPerPage {
+			break
+		}
+	}
+	return newest, nil
+}
+
+// hasPlatformAsset reports whether release has an asset matching config.AssetPattern for the target platform.
+func hasPlatformAsset(config UpdateConfig, release *GitHubRelease) bool {
+	targetOS, targetArch := resolvePlatform(config)
+	_, err := findMatchingAsset(config, release, targetOS, targetArch)
+	return !errors.Is(err, ErrNoMatchingAsset)
+}
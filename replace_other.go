@@ -0,0 +1,46 @@
+//go:build !unix
+
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+)
+
+// replaceExecutable replaces dst with a copy of src by overwriting it in place, flushed to disk.
+// On Windows, a running executable cannot be renamed over, so the file is rewritten
+// once the process that used it has exited.
+func replaceExecutable(src, dst string) error {
+	return copyFile(src, dst)
+}
+
+// renameExecutable moves src over dst, which must be on the same volume. Unlike
+// replaceExecutable, src is consumed. Windows allows renaming the running src, but not replacing
+// a dst that is still in use.
+//
+// It returns an error if the rename fails.
+func renameExecutable(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move new executable into place at %q: %w", dst, err)
+	}
+	return nil
+}
+
+// replaceRunningExecutable replaces dst with a copy of src while dst is still executing.
+// Windows refuses to overwrite a running executable but allows renaming it, so dst is first
+// moved aside to dst + ".old" and restored if the copy fails. The old file is removed on the
+// next replacement, once it is no longer in use.
+func replaceRunningExecutable(src, dst string) error {
+	old := dst + ".old"
+	os.Remove(old)
+
+	if err := os.Rename(dst, old); err != nil {
+		return fmt.Errorf("failed to move running executable %q aside: %w", dst, err)
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		os.Rename(old, dst)
+		return err
+	}
+	return nil
+}
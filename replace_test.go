@@ -0,0 +1,29 @@
+package ghupdate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "new")
+	dst := filepath.Join(dir, "app")
+	if err := os.WriteFile(src, []byte("new version"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old version, which is longer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatalf("replaceExecutable: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "new version" {
+		t.Errorf("dst holds %q (%v), want %q", data, err, "new version")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("src was consumed: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// replaceExecutable replaces dst with a copy of src.
+// The copy is written to a temporary file in the destination directory and renamed over dst,
+// which is atomic on every Unix-like system and succeeds even while dst is being executed
+// (where writing to it in place fails with ETXTBSY on Linux, illumos and some BSDs).
+// The copy is flushed to disk before the rename, and the directory afterwards, so a power loss
+// cannot leave a truncated executable behind.
+// The owner and group of an existing dst are preserved when permitted. If dst is a symlink,
+// as is common for executables in Termux's $PREFIX/bin or Homebrew prefixes, the file it
+// points to is replaced and the link is left intact.
+//
+// Temporary files left behind by an interrupted earlier attempt are removed first.
+//
+// It returns an error if the temporary copy cannot be written or the rename fails.
+func replaceExecutable(src, dst string) error {
+	if resolved, err := filepath.EvalSymlinks(dst); err == nil {
+		dst = resolved
+	}
+
+	if leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".new-*")); err == nil {
+		for _, leftover := range leftovers {
+			os.Remove(leftover)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file next to %q: %w", dst, err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+
+	if err := copyFile(src, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if info, err := os.Stat(dst); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			// Best effort: only privileged processes can hand files to other users.
+			os.Chown(tmpPath, int(stat.Uid), int(stat.Gid))
+		}
+	}
+
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move new executable into place at %q: %w", dst, err)
+	}
+	syncDir(filepath.Dir(dst))
+	return nil
+}
+
+// renameExecutable moves src over dst, which must be on the same file system, keeping the owner
+// and group of an existing dst when permitted. If dst is a symlink, the file it points to is
+// replaced. Unlike replaceExecutable, src is consumed. As there, src is flushed to disk before
+// the rename and the directory afterwards.
+//
+// It returns an error if src cannot be flushed or the rename fails.
+func renameExecutable(src, dst string) error {
+	if resolved, err := filepath.EvalSymlinks(dst); err == nil {
+		dst = resolved
+	}
+	if info, err := os.Stat(dst); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			// Best effort: only privileged processes can hand files to other users.
+			os.Chown(src, int(stat.Uid), int(stat.Gid))
+		}
+	}
+	if err := syncFile(src); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move new executable into place at %q: %w", dst, err)
+	}
+	syncDir(filepath.Dir(dst))
+	return nil
+}
+
+// syncFile flushes the file at path to disk.
+func syncFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to flush %q to disk: %w", path, err)
+	}
+	return nil
+}
+
+// syncDir flushes the directory entries of dir to disk, so that a rename into it is durable.
+// It is best effort: some file systems do not support syncing directories.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// replaceRunningExecutable replaces dst with a copy of src while dst may still be executing.
+// Renaming over a running executable is permitted on Unix-like systems, so this is the same as
+// replaceExecutable.
+func replaceRunningExecutable(src, dst string) error {
+	return replaceExecutable(src, dst)
+}
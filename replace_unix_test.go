@@ -0,0 +1,65 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceExecutableWhileRunning(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "app")
+	if err := copyFile(exe, dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	startHelperProcess(t, dst)
+
+	src := filepath.Join(dir, "new")
+	if err := os.WriteFile(src, []byte("new version"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatalf("replaceExecutable while dst is running: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "new version" {
+		t.Errorf("dst holds %.20q (%v), want %q", data, err, "new version")
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".app.new-*")); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %q", leftovers)
+	}
+}
+
+func TestReplaceExecutableThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "app-1.0")
+	link := filepath.Join(dir, "app")
+	src := filepath.Join(dir, "new")
+	if err := os.WriteFile(target, []byte("old version"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("new version"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceExecutable(src, link); err != nil {
+		t.Fatalf("replaceExecutable: %v", err)
+	}
+	if resolved, err := os.Readlink(link); err != nil || resolved != target {
+		t.Errorf("link points to %q (%v), want %q", resolved, err, target)
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "new version" {
+		t.Errorf("link target holds %q (%v), want %q", data, err, "new version")
+	}
+}
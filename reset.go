@@ -0,0 +1,92 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResetState removes all updater state kept for config.DataDir, to recover from corrupted state
+// (e.g., from a "myapp update reset" command): staged and partially downloaded updates, including
+// those staged in AltStagingDir or next to ExecutablePath, the update history, failure records and
+// channel choice, the release response cache, the content-addressed download store, auxiliary
+// files staged from archives, trusted TUF metadata and the executables kept for Rollback. Other
+// files in DataDir are left alone. Lock files need no reset, since locks of processes that are no
+// longer running are taken over automatically.
+//
+// The state file being reset may itself be corrupted, so the staging location it records is only
+// trusted if it is one the updater could have chosen for config, and staged updates are removed
+// as single files, never recursively.
+//
+// With dryRun, nothing is removed and the paths that would be removed are listed.
+//
+// It returns the paths removed (or that would be removed), or an error if one cannot be removed.
+func ResetState(config UpdateConfig, dryRun bool) ([]string, error) {
+	dataDir := config.DataDir
+	if dataDir == "" {
+		return nil, fmt.Errorf("invalid config: DataDir is required")
+	}
+
+	var files []string
+	for _, path := range stagedUpdatePaths(config) {
+		files = append(files, path, partialDownloadPath(path), resumeInfoPath(partialDownloadPath(path)), extractionPath(path))
+	}
+	files = append(files, encryptedUpdatePath(dataDir))
+	for _, name := range []string{stateFileName, stateFileName + ".tmp", releaseCacheFileName} {
+		files = append(files, filepath.Join(dataDir, name))
+	}
+	var dirs []string
+	for _, name := range []string{localStoreDirName, "tuf", archiveFilesDirName, backupDirName} {
+		dirs = append(dirs, filepath.Join(dataDir, name))
+	}
+
+	var removed []string
+	seen := make(map[string]bool)
+	remove := func(path string, recursive bool) error {
+		if seen[path] {
+			return nil
+		}
+		seen[path] = true
+		info, err := os.Lstat(path)
+		if err != nil || (!recursive && info.IsDir()) {
+			return nil
+		}
+		if !dryRun {
+			removeFunc := os.Remove
+			if recursive {
+				removeFunc = os.RemoveAll
+			}
+			if err := removeFunc(path); err != nil {
+				return fmt.Errorf("failed to remove %q: %w", path, err)
+			}
+		}
+		removed = append(removed, path)
+		return nil
+	}
+	for _, path := range files {
+		if err := remove(path, false); err != nil {
+			return removed, err
+		}
+	}
+	for _, path := range dirs {
+		if err := remove(path, true); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// stagedUpdatePaths returns the paths an update for config may be staged at (see
+// chooseStagedUpdatePath). The location recorded in the state file is included only if it is one
+// of them.
+func stagedUpdatePaths(config UpdateConfig) []string {
+	defaultPath, altPath, nextToExecutable := stagingLocations(config)
+	paths := []string{defaultPath}
+	recorded := preparedUpdatePath(config.DataDir)
+	for _, path := range []string{altPath, nextToExecutable} {
+		if path != "" && path == recorded {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,72 @@
+package ghupdate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResetStateDistrustsRecordedStagingPath(t *testing.T) {
+	dataDir := t.TempDir()
+	victim := t.TempDir()
+	if err := os.WriteFile(filepath.Join(victim, "keep"), []byte("user data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := UpdateConfig{DataDir: dataDir, ExecutablePath: filepath.Join(t.TempDir(), "myapp")}
+	if err := updateState(config, func(state *updaterState) { state.StagedPath = victim }); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := ResetState(config, false)
+	if err != nil {
+		t.Fatalf("ResetState: %v", err)
+	}
+	if slices.Contains(removed, victim) {
+		t.Errorf("ResetState removed the recorded staging path %s", victim)
+	}
+	if _, err := os.Stat(filepath.Join(victim, "keep")); err != nil {
+		t.Errorf("file in the recorded staging path was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, stateFileName)); !os.IsNotExist(err) {
+		t.Errorf("state file was not removed: %v", err)
+	}
+}
+
+func TestResetStateRemovesUpdateStagedNextToExecutable(t *testing.T) {
+	dataDir := t.TempDir()
+	config := UpdateConfig{DataDir: dataDir, ExecutablePath: filepath.Join(t.TempDir(), "myapp")}
+	_, _, staged := stagingLocations(config)
+	for _, path := range []string{staged, partialDownloadPath(staged), stagedUpdatePath(dataDir)} {
+		if err := os.WriteFile(path, []byte("update"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := updateState(config, func(state *updaterState) { state.StagedPath = staged }); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := ResetState(config, true)
+	if err != nil {
+		t.Fatalf("ResetState dry run: %v", err)
+	}
+	if _, err := os.Stat(staged); err != nil {
+		t.Errorf("dry run removed %s: %v", staged, err)
+	}
+
+	removed, err := ResetState(config, false)
+	if err != nil {
+		t.Fatalf("ResetState: %v", err)
+	}
+	if !slices.Equal(listed, removed) {
+		t.Errorf("dry run listed %q, but %q were removed", listed, removed)
+	}
+	for _, path := range []string{staged, partialDownloadPath(staged), stagedUpdatePath(dataDir)} {
+		if !slices.Contains(removed, path) {
+			t.Errorf("ResetState did not remove %s", path)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resumeInfo is recorded next to a partial download and identifies the asset it is a prefix of,
+// so that a download is only ever resumed from a file holding the start of the same asset.
+type resumeInfo struct {
+	URL          string `json:"url"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// resumeInfoPath returns the path of the record describing the partial download at destPath.
+func resumeInfoPath(destPath string) string {
+	return destPath + ".resume"
+}
+
+// resumeOffset returns the number of bytes of a previous, interrupted download of url, an asset of
+// expectedSize bytes, already present at destPath, and the validator to send in an If-Range header
+// so that the server only continues it if the asset has not changed since. It returns 0 if the
+// download must start over: when the file is not shorter than expectedSize, or the record next to
+// it (see saveResumeInfo) is missing, describes another URL or size, or holds no strong validator.
+func resumeOffset(destPath, url string, expectedSize int64) (int64, string) {
+	if expectedSize <= 0 {
+		return 0, ""
+	}
+	info, err := os.Stat(destPath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() >= expectedSize {
+		return 0, ""
+	}
+	data, err := os.ReadFile(resumeInfoPath(destPath))
+	if err != nil {
+		return 0, ""
+	}
+	var record resumeInfo
+	if err := json.Unmarshal(data, &record); err != nil || record.URL != url || record.Size != expectedSize {
+		return 0, ""
+	}
+	validator := ifRangeValidator(record.ETag, record.LastModified)
+	if validator == "" {
+		return 0, ""
+	}
+	return info.Size(), validator
+}
+
+// ifRangeValidator returns the value for an If-Range header from a response's ETag and
+// Last-Modified headers: the ETag unless it is weak, which If-Range does not accept, or else the
+// modification date. It returns an empty string if neither can be used.
+func ifRangeValidator(etag, lastModified string) string {
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return lastModified
+}
+
+// saveResumeInfo records that the download at destPath, starting afresh with resp, is of url, an
+// asset of expectedSize bytes, so that it can be resumed if interrupted (see resumeOffset). Nothing
+// is recorded if the size is unknown or resp carries no validator for If-Range.
+func saveResumeInfo(config UpdateConfig, destPath, url string, expectedSize int64, resp *http.Response) {
+	record := resumeInfo{
+		URL:          url,
+		Size:         expectedSize,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if expectedSize <= 0 || ifRangeValidator(record.ETag, record.LastModified) == "" {
+		os.Remove(resumeInfoPath(destPath))
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	os.WriteFile(resumeInfoPath(destPath), data, fileMode(config)&^0111)
+}
+
+// resumedAt reports whether resp is a partial response continuing a download at offset,
+// as requested with a Range header.
+func resumedAt(resp *http.Response, offset int64) bool {
+	if resp.StatusCode != http.StatusPartialContent {
+		return false
+	}
+	// Content-Range: bytes <start>-<end>/<total>
+	spec, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return err == nil && n == offset
+}
+
+// partialDownloadPath returns the path an asset destined for destPath is downloaded to before it is verified.
+func partialDownloadPath(destPath string) string {
+	return destPath + ".partial"
+}
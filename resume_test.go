@@ -0,0 +1,141 @@
+package ghupdate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveAsset serves content with an ETag, honoring Range and If-Range requests, and records the
+// Range header of each request.
+func serveAsset(t *testing.T, content []byte, etag string, ranges *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadVerifiedAssetDoesNotSpliceStalePartial(t *testing.T) {
+	newContent := bytes.Repeat([]byte("N"), 100)
+	var ranges []string
+	server := serveAsset(t, newContent, `"new"`, &ranges)
+
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "update")
+	partialPath := partialDownloadPath(destPath)
+	// A partial download of an older release, recorded as resumable from the same URL
+	if err := os.WriteFile(partialPath, bytes.Repeat([]byte("O"), 60), 0644); err != nil {
+		t.Fatal(err)
+	}
+	record := `{"url":"` + server.URL + `/asset","size":100,"etag":"\"new\""}`
+	if err := os.WriteFile(resumeInfoPath(partialPath), []byte(record), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a digest, nothing would catch a splice, so the download must start over
+	asset := &GitHubAsset{Name: "app", BrowserDownloadURL: server.URL + "/asset", Size: 100}
+	release := &GitHubRelease{TagName: "v2.0.0", Assets: []GitHubAsset{*asset}}
+	if _, err := downloadVerifiedAsset(UpdateConfig{}, release, asset, destPath); err != nil {
+		t.Fatalf("downloadVerifiedAsset: %v", err)
+	}
+	if data, _ := os.ReadFile(destPath); !bytes.Equal(data, newContent) {
+		t.Errorf("staged %q, want the new asset only", data)
+	}
+	if len(ranges) != 1 || ranges[0] != "" {
+		t.Errorf("requests with Range headers %q, want a single full request", ranges)
+	}
+}
+
+func TestDownloadAssetResume(t *testing.T) {
+	newContent := []byte(strings.Repeat("N", 100))
+	sum := sha256.Sum256(newContent)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		partial    string
+		record     string // with %s standing for the asset URL
+		wantRanges []string
+	}{
+		{"matching record", strings.Repeat("N", 60), `{"url":"%s","size":100,"etag":"\"new\""}`, []string{"bytes=60-"}},
+		{"no record", strings.Repeat("O", 60), "", []string{""}},
+		{"other URL", strings.Repeat("O", 60), `{"url":"%s/old","size":100,"etag":"\"new\""}`, []string{""}},
+		{"other size", strings.Repeat("O", 60), `{"url":"%s","size":90,"etag":"\"new\""}`, []string{""}},
+		{"weak ETag only", strings.Repeat("O", 60), `{"url":"%s","size":100,"etag":"W/\"new\""}`, []string{""}},
+		// The server ignores the range when the If-Range validator no longer matches
+		{"changed asset", strings.Repeat("O", 60), `{"url":"%s","size":100,"etag":"\"old\""}`, []string{"bytes=60-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			server := serveAsset(t, newContent, `"new"`, &ranges)
+			url := server.URL + "/asset"
+
+			dir := t.TempDir()
+			destPath := filepath.Join(dir, "update")
+			partialPath := partialDownloadPath(destPath)
+			if err := os.WriteFile(partialPath, []byte(tt.partial), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.record != "" {
+				record := strings.Replace(tt.record, "%s", url, 1)
+				if err := os.WriteFile(resumeInfoPath(partialPath), []byte(record), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			asset := &GitHubAsset{Name: "app", BrowserDownloadURL: url, Size: 100, Digest: digest}
+			release := &GitHubRelease{TagName: "v2.0.0", Assets: []GitHubAsset{*asset}}
+			if _, err := downloadVerifiedAsset(UpdateConfig{}, release, asset, destPath); err != nil {
+				t.Fatalf("downloadVerifiedAsset: %v", err)
+			}
+			if data, _ := os.ReadFile(destPath); !bytes.Equal(data, newContent) {
+				t.Errorf("staged %q, want the new asset", data)
+			}
+			if strings.Join(ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("requests with Range headers %q, want %q", ranges, tt.wantRanges)
+			}
+			if _, err := os.Stat(resumeInfoPath(partialPath)); !os.IsNotExist(err) {
+				t.Errorf("resume record left behind after the download completed: %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadAssetRecordsResumeInfo(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"new"`)
+		w.Header().Set("Content-Length", "100")
+		w.Write(bytes.Repeat([]byte("N"), 60)) // the connection drops early
+	}))
+	defer server.Close()
+
+	destPath := filepath.Join(t.TempDir(), "update.partial")
+	if _, err := downloadAsset(UpdateConfig{}, server.URL, destPath, 100, true); err == nil {
+		t.Fatal("downloadAsset succeeded on a truncated body")
+	}
+	offset, validator := resumeOffset(destPath, server.URL, 100)
+	if offset != 60 || validator != `"new"` {
+		t.Errorf("resumeOffset = %d, %q, want 60, %q", offset, validator, `"new"`)
+	}
+
+	if _, err := downloadAsset(UpdateConfig{}, server.URL, destPath, 100, false); err == nil {
+		t.Fatal("downloadAsset succeeded on a truncated body")
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Errorf("non-resumable partial download kept: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package ghupdate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how GitHub API requests and asset downloads are retried after transient
+// failures such as 5xx responses, rate limiting or dropped connections. Zero fields take their
+// value from DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Set it to 1 to disable retries.
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the delay before the first retry. It doubles with every
+	// further retry, and the actual delay is chosen uniformly at random up to the bound (full jitter).
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay bound between attempts.
+	MaxBackoff time.Duration
+	// RetryableStatusCodes lists the HTTP status codes that are retried.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy is the retry policy used when UpdateConfig.Retry is nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	RetryableStatusCodes: []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// retryPolicy returns the effective retry policy for config, filling unset fields from DefaultRetryPolicy.
+func retryPolicy(config UpdateConfig) RetryPolicy {
+	if config.Retry == nil {
+		return DefaultRetryPolicy
+	}
+	policy := *config.Retry
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if policy.RetryableStatusCodes == nil {
+		policy.RetryableStatusCodes = DefaultRetryPolicy.RetryableStatusCodes
+	}
+	return policy
+}
+
+// backoff returns the randomized delay before retry number attempt (starting at 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	bound := p.InitialBackoff
+	for i := 1; i < attempt && bound < p.MaxBackoff; i++ {
+		bound *= 2
+	}
+	bound = min(bound, p.MaxBackoff)
+	return rand.N(bound + 1)
+}
+
+// retryable reports whether err is a transient failure worth retrying: a retryable HTTP status,
+// a network error, or a connection dropped mid-transfer.
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var status *statusError
+	if errors.As(err, &status) {
+		return slices.Contains(p.RetryableStatusCodes, status.StatusCode)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errIncompleteDownload)
+}
+
+// withRetry runs op until it succeeds, fails with a non-retryable error, or the policy's attempts are exhausted.
+//
+// It returns the error of the last attempt.
+func withRetry(config UpdateConfig, op func() error) error {
+	policy := retryPolicy(config)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = op()
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+		time.Sleep(policy.backoff(attempt))
+	}
+}
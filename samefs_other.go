@@ -0,0 +1,17 @@
+//go:build !unix
+
+package ghupdate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// sameFileSystem reports whether the paths a and b are on the same volume, so that one can be
+// renamed to the other. Only the volume names are compared, so directories mounted into another
+// volume are not told apart.
+func sameFileSystem(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && strings.EqualFold(filepath.VolumeName(absA), filepath.VolumeName(absB))
+}
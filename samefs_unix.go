@@ -0,0 +1,24 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os"
+	"syscall"
+)
+
+// sameFileSystem reports whether the paths a and b, which must exist, are on the same file system,
+// so that one can be renamed to the other. It returns false if either cannot be inspected.
+func sameFileSystem(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	statA, okA := infoA.Sys().(*syscall.Stat_t)
+	statB, okB := infoB.Sys().(*syscall.Stat_t)
+	return okA && okB && statA.Dev == statB.Dev
+}
@@ -0,0 +1,143 @@
+package ghupdate
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultCheckInterval is the interval between background checks when SchedulerOptions.Interval is unset.
+	defaultCheckInterval = 24 * time.Hour
+	// schedulerPollInterval is how often the scheduler compares the wall clock against the next check
+	// time, so checks missed while the machine was suspended are caught up promptly.
+	schedulerPollInterval = time.Minute
+	// clockSkewTolerance is how far in the future a persisted timestamp may be before it is
+	// considered invalid, e.g. because the system clock was set back.
+	clockSkewTolerance = 5 * time.Minute
+)
+
+// SchedulerOptions configures RunScheduler.
+type SchedulerOptions struct {
+	// Interval is the time between checks. If zero, 24 hours is used.
+	Interval time.Duration
+	// AppName is used in notifications. If empty, the repository name is used.
+	AppName string
+	// Notify enables a native desktop notification (see Notify) when a new update has been staged.
+	Notify bool
+	// OnUpdateStaged is called after a new update has been downloaded and staged.
+	OnUpdateStaged func(*UpdateInfo)
+	// OnNotificationClick is called when the user clicks the notification, where supported.
+	// Applications typically call ApplyUpdate from it.
+	OnNotificationClick func(*UpdateInfo)
+	// OnError is called when a check fails. Errors are otherwise ignored and checking continues.
+	OnError func(error)
+}
+
+// RunScheduler checks for updates with CheckAndPrepareUpdate and then every opts.Interval until ctx
+// is cancelled, staging updates in the background. Each newly staged version is reported once through
+// OnUpdateStaged and, if enabled, a desktop notification such as
+// "MyApp v1.4.0 is ready to install. Restart to apply." Applying the update is left to the application.
+// Updates deferred with DeferUpdate are announced once the deferral has expired.
+//
+// The time of the last check is persisted in DataDir, so restarting the application does not check
+// again before the interval has elapsed. Intervals are measured on the monotonic clock and are
+// therefore immune to the system clock being changed; a check is also made once the wall clock shows
+// the interval elapsed, which covers time spent suspended. A persisted check time in the future is
+// treated as invalid and causes an immediate check.
+//
+// It blocks until ctx is done and returns ctx.Err(); run it in its own goroutine.
+func RunScheduler(ctx context.Context, config UpdateConfig, opts SchedulerOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+	name := opts.AppName
+	if name == "" {
+		name = config.GitHubRepo
+	}
+
+	var announced string
+	delay := firstCheckDelay(config.DataDir, interval, time.Now())
+	for {
+		if err := waitUntilDue(ctx, delay); err != nil {
+			return err
+		}
+		delay = interval
+
+		updateState(config, func(state *updaterState) {
+			state.LastCheck = time.Now().Round(0).UTC()
+		})
+		info, err := CheckAndPrepareUpdate(config)
+		switch {
+		case err != nil:
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+		case info != nil && info.DeferredUntil.IsZero() && info.LatestVersion != announced:
+			announced = info.LatestVersion
+			if opts.OnUpdateStaged != nil {
+				opts.OnUpdateStaged(info)
+			}
+			if opts.Notify {
+				notifyStaged(name, info, opts)
+			}
+		}
+	}
+}
+
+// firstCheckDelay returns how long to wait before the first check, based on the last check time
+// persisted in dataDir. It is zero if there was no previous check, if it is at least interval ago, or
+// if it lies in the future, which means the persisted time or the system clock cannot be trusted.
+func firstCheckDelay(dataDir string, interval time.Duration, now time.Time) time.Duration {
+	state, err := loadState(dataDir)
+	if err != nil || state.LastCheck.IsZero() {
+		return 0
+	}
+	elapsed := now.Round(0).Sub(state.LastCheck)
+	if elapsed < -clockSkewTolerance || elapsed >= interval {
+		return 0
+	}
+	return max(interval-elapsed, 0)
+}
+
+// waitUntilDue blocks until delay has passed on either the monotonic or the wall clock, or until
+// ctx is done. The monotonic clock is unaffected by clock changes but stops while the machine is
+// suspended on some systems; the wall clock keeps running, so whichever elapses first wins.
+// A forward jump of the wall clock can therefore cause at most one early check.
+//
+// It returns ctx.Err() if ctx is done first, or nil otherwise.
+func waitUntilDue(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	start := time.Now()
+	wallStart := start.Round(0)
+
+	ticker := time.NewTicker(min(delay, schedulerPollInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if time.Since(start) >= delay || time.Now().Round(0).Sub(wallStart) >= delay {
+			return nil
+		}
+	}
+}
+
+// notifyStaged shows the desktop notification for a staged update.
+func notifyStaged(name string, info *UpdateInfo, opts SchedulerOptions) {
+	n := Notification{
+		Title:   name,
+		Message: fmt.Sprintf("%s %s is ready to install. Restart to apply.", name, info.LatestVersion),
+	}
+	if opts.OnNotificationClick != nil {
+		n.OnClick = func() { opts.OnNotificationClick(info) }
+	}
+	if err := Notify(n); err != nil && opts.OnError != nil {
+		opts.OnError(err)
+	}
+}
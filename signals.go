@@ -0,0 +1,96 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// UpdateModeOptions customizes how HandleUpdateModeWithOptions applies an update.
+type UpdateModeOptions struct {
+	// OnDeferredSignal is called from a separate goroutine when an interrupt or termination signal
+	// (SIGINT, SIGTERM, SIGHUP, or CTRL_C, CTRL_BREAK and CTRL_CLOSE on Windows) arrives during the
+	// replacement window. The signal itself is deferred until the executable has been replaced.
+	OnDeferredSignal func(os.Signal)
+	// ContinueAfterSignal lets the updated application start normally after a signal was deferred.
+	// By default, the process honors the deferred signal by exiting with status 128+signal as soon
+	// as the replacement window closes, just as a service manager stopping it would expect.
+	ContinueAfterSignal bool
+	// ProgressDialog, if set, is shown in a minimal native window while update mode waits for the old
+	// process and replaces the executable, so users of GUI applications do not think the application
+	// silently failed to start. It uses PowerShell on Windows, osascript on macOS and zenity elsewhere.
+	ProgressDialog *ProgressDialog
+	// Relaunch starts the installed executable once the update has been applied, with the original
+	// arguments (if forwarded, see UpdateConfig.ForwardArguments), environment and working directory,
+	// instead of continuing to run the staged copy of the update. On Unix the update process is
+	// replaced by it, keeping its PID; on Windows the update process waits for it and exits with its
+	// exit code. If the relaunch fails, the application continues in the update process.
+	Relaunch bool
+}
+
+// ProgressDialog is the text of the window shown by update mode (see UpdateModeOptions.ProgressDialog).
+type ProgressDialog struct {
+	// Title is the window title, typically the application name.
+	Title string
+	// Message is the text shown in the window. If empty, "Installing update..." is used.
+	Message string
+}
+
+// deferSignals starts intercepting replacementSignals so that they cannot kill the process halfway
+// through replacing the executable. onSignal, if set, is called for every intercepted signal.
+//
+// It returns a function that stops intercepting and reports the first signal received, or nil.
+func deferSignals(onSignal func(os.Signal)) func() os.Signal {
+	ch := make(chan os.Signal, 1)
+	first := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, replacementSignals...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				select {
+				case first <- sig:
+				default:
+				}
+				if onSignal != nil {
+					onSignal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() os.Signal {
+		signal.Stop(ch)
+		close(done)
+		select {
+		case sig := <-first:
+			return sig
+		default:
+			return nil
+		}
+	}
+}
+
+// signalExitCode returns the conventional exit status of a process terminated by sig.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// exitOnDeferredSignal stops intercepting signals with stop, as returned by deferSignals, and ends
+// the process with status 128+signal if one arrived meanwhile, as it would have without the
+// deferral. It is deferred by the in-process replacement paths, which have no
+// UpdateModeOptions.ContinueAfterSignal to honor.
+func exitOnDeferredSignal(stop func() os.Signal) {
+	if sig := stop(); sig != nil {
+		fmt.Fprintf(os.Stderr, "Executable replaced; exiting on deferred signal %v\n", sig)
+		os.Exit(signalExitCode(sig))
+	}
+}
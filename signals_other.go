@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package ghupdate
+
+import "os"
+
+// replacementSignals are the signals deferred during the replacement window. Only os.Interrupt is
+// portable to platforms without Unix or Windows signals.
+var replacementSignals = []os.Signal{os.Interrupt}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os"
+	"syscall"
+)
+
+// replacementSignals are the signals deferred during the replacement window.
+var replacementSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
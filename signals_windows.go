@@ -0,0 +1,10 @@
+package ghupdate
+
+import (
+	"os"
+	"syscall"
+)
+
+// replacementSignals are the signals deferred during the replacement window. Go delivers
+// CTRL_C and CTRL_BREAK as os.Interrupt, and CTRL_CLOSE, CTRL_LOGOFF and CTRL_SHUTDOWN as SIGTERM.
+var replacementSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
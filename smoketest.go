@@ -0,0 +1,106 @@
+package ghupdate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSmokeTestTimeout bounds a smoke test run when UpdateConfig.SmokeTestTimeout is unset.
+	defaultSmokeTestTimeout = 10 * time.Second
+	// smokeTestMaxOutput caps the output captured from a smoke test run.
+	smokeTestMaxOutput = 64 * 1024
+)
+
+// cappedBuffer is an io.Writer that keeps at most max bytes and silently discards the rest.
+type cappedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	if room := c.max - c.buf.Len(); room > 0 {
+		if len(p) > room {
+			c.buf.Write(p[:room])
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// smokeTestUpdate executes the staged update with config.SmokeTestArgs and checks that it exits
+// successfully and that its output mentions the expected version (with or without a leading "v").
+//
+// The binary runs with a timeout, no stdin, a throwaway working and home directory, and a minimal
+// environment, so it cannot pick up the user's configuration or linger in the background.
+// The GHUPDATE_SMOKE_TEST variable is set to let applications detect the check.
+// The test is skipped when the asset targets a different platform than the running one.
+//
+// It returns an error if the binary cannot be started, times out, fails, or does not report the version.
+func smokeTestUpdate(config UpdateConfig, updatePath, version string) error {
+	if len(config.SmokeTestArgs) == 0 {
+		return nil
+	}
+	if targetOS, targetArch := resolvePlatform(config); targetOS != runtime.GOOS || targetArch != runtime.GOARCH {
+		return nil // Cannot execute binaries built for another platform
+	}
+
+	timeout := config.SmokeTestTimeout
+	if timeout <= 0 {
+		timeout = defaultSmokeTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	sandbox, err := os.MkdirTemp("", "ghupdate-smoke-*")
+	if err != nil {
+		return fmt.Errorf("failed to create smoke test directory: %w", err)
+	}
+	defer os.RemoveAll(sandbox)
+
+	output := &cappedBuffer{max: smokeTestMaxOutput}
+	cmd := exec.CommandContext(ctx, updatePath, config.SmokeTestArgs...)
+	cmd.Dir = sandbox
+	cmd.Env = smokeTestEnv(sandbox)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("smoke test of %q timed out after %s", updatePath, timeout)
+		}
+		return fmt.Errorf("smoke test of %q failed: %w: %s", updatePath, err, strings.TrimSpace(output.buf.String()))
+	}
+
+	if !strings.Contains(output.buf.String(), strings.TrimPrefix(version, "v")) {
+		return fmt.Errorf("smoke test of %q did not report expected version %s: %s", updatePath, version, strings.TrimSpace(output.buf.String()))
+	}
+	return nil
+}
+
+// smokeTestEnv builds the minimal environment for a smoke test run, pointing home and temporary
+// directories at the sandbox.
+func smokeTestEnv(sandbox string) []string {
+	env := []string{
+		"GHUPDATE_SMOKE_TEST=1",
+		"HOME=" + sandbox,
+		"TMPDIR=" + sandbox,
+	}
+	for _, name := range []string{"PATH", "SYSTEMROOT", "WINDIR", "LANG"} {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		env = append(env, "USERPROFILE="+sandbox, "TEMP="+sandbox, "TMP="+sandbox)
+	}
+	return env
+}
@@ -0,0 +1,106 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// chooseStagedUpdatePath picks the path at which the update executable is staged.
+// The default location in DataDir (see stagedUpdatePath) is used unless it is on a noexec mount,
+// in which case config.AltStagingDir and then the directory of the executable being updated are
+// tried. If every candidate is noexec, the default is used and ApplyUpdate falls back to
+// in-process replacement. With config.StageNextToExecutable, the directory of the executable is
+// tried first if DataDir is on another file system, so the update can be renamed into place.
+//
+// The choice is recorded in DataDir so ApplyUpdate and CleanupUpdate find the staged file.
+func chooseStagedUpdatePath(config UpdateConfig) (string, error) {
+	defaultPath, altPath, nextToExecutable := stagingLocations(config)
+	candidates := []string{defaultPath}
+	if altPath != "" {
+		candidates = append(candidates, altPath)
+	}
+	if nextToExecutable != "" {
+		dir := filepath.Dir(nextToExecutable)
+		if config.StageNextToExecutable && isWritableDir(dir) && !onFileSystemOf(filepath.Dir(defaultPath), dir, dirMode(config)) {
+			candidates = append([]string{nextToExecutable}, candidates...)
+		} else {
+			candidates = append(candidates, nextToExecutable)
+		}
+	}
+
+	chosen := defaultPath
+	for _, candidate := range candidates {
+		dir := filepath.Dir(candidate)
+		if err := os.MkdirAll(dir, dirMode(config)); err != nil {
+			continue
+		}
+		if !isNoexecDir(dir) {
+			chosen = candidate
+			break
+		}
+	}
+
+	if err := updateState(config, func(state *updaterState) {
+		state.StagedPath = ""
+		if chosen != defaultPath {
+			state.StagedPath = chosen
+		}
+	}); err != nil {
+		return "", fmt.Errorf("failed to record staging location: %w", err)
+	}
+	return chosen, nil
+}
+
+// stagingLocations returns the paths chooseStagedUpdatePath picks from: the default location in
+// DataDir, the one in config.AltStagingDir and the one next to config.ExecutablePath. The latter two
+// are empty if not configured.
+func stagingLocations(config UpdateConfig) (defaultPath, altPath, nextToExecutable string) {
+	defaultPath = stagedUpdatePath(config.DataDir)
+	if config.AltStagingDir != "" {
+		altPath = filepath.Join(config.AltStagingDir, "update"+getExecutableExtension())
+	}
+	if config.ExecutablePath != "" {
+		dir, name := filepath.Split(config.ExecutablePath)
+		nextToExecutable = filepath.Join(dir, "."+name+".update"+getExecutableExtension())
+	}
+	return defaultPath, altPath, nextToExecutable
+}
+
+// preparedUpdatePath returns the path at which the update executable for dataDir was staged,
+// as recorded by chooseStagedUpdatePath.
+func preparedUpdatePath(dataDir string) string {
+	if state, err := loadState(dataDir); err == nil && state.StagedPath != "" {
+		return state.StagedPath
+	}
+	return stagedUpdatePath(dataDir)
+}
+
+// isWritableDir reports whether files can be created in dir.
+func isWritableDir(dir string) bool {
+	f, err := os.CreateTemp(dir, ".ghupdate-write-probe-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// onFileSystemOf reports whether the directory dir, created with mode if missing, is on the same
+// file system as other.
+func onFileSystemOf(dir, other string, mode os.FileMode) bool {
+	if err := os.MkdirAll(dir, mode); err != nil {
+		return false
+	}
+	return sameFileSystem(dir, other)
+}
+
+// stagedNextTo reports whether the staged update at updatePath is in the directory of the
+// executable at exePath (see UpdateConfig.StageNextToExecutable).
+func stagedNextTo(updatePath, exePath string) bool {
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	return filepath.Dir(filepath.Clean(updatePath)) == filepath.Dir(exePath)
+}
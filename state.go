@@ -0,0 +1,107 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFileName is the name of the file in DataDir that holds persistent updater state.
+const stateFileName = "state.json"
+
+// updaterState is the persistent updater state kept in DataDir.
+type updaterState struct {
+	// HighestVersion is the highest version ever observed running from this installation.
+	HighestVersion string `json:"highest_version,omitempty"`
+	// Checksums maps versions staged by ghupdate to the hex-encoded SHA-256 of their executable.
+	Checksums map[string]string `json:"checksums,omitempty"`
+	// StagedPath is the location of the staged update executable when it is not the default
+	// location in DataDir.
+	StagedPath string `json:"staged_path,omitempty"`
+	// Channel is the update channel chosen with SwitchChannel. Empty means ChannelStable.
+	Channel string `json:"channel,omitempty"`
+	// SwitchTarget is a release older than the running version that SwitchChannel allowed to be installed.
+	SwitchTarget string `json:"switch_target,omitempty"`
+	// LastCheck is the wall-clock time of the last check made by RunScheduler.
+	LastCheck time.Time `json:"last_check,omitzero"`
+	// StagedVersion is the version of the most recently staged update.
+	StagedVersion string `json:"staged_version,omitempty"`
+	// JustUpdated is the completed update not yet reported by WasJustUpdated.
+	JustUpdated *UpdateRecord `json:"just_updated,omitempty"`
+	// History lists the most recent completed updates, oldest first.
+	History []UpdateRecord `json:"history,omitempty"`
+	// Failures lists the releases that failed verification or installation (see CircuitBreakerPolicy).
+	Failures []VersionFailures `json:"failures,omitempty"`
+	// PinnedVersion is the version set with Pin, if any.
+	PinnedVersion string `json:"pinned_version,omitempty"`
+	// Deferral is the "remind me later" choice set with DeferUpdate, if any.
+	Deferral *deferral `json:"deferral,omitempty"`
+	// ApplyOnLaunch is the staged version scheduled with ScheduleUpdateOnNextLaunch, if any.
+	ApplyOnLaunch string `json:"apply_on_launch,omitempty"`
+	// Backups lists the replaced executables kept for Rollback, oldest first.
+	Backups []Backup `json:"backups,omitempty"`
+	// Probation is the installed update watched by the post-update watchdog, if any.
+	Probation *probation `json:"probation,omitempty"`
+}
+
+// loadState reads the updater state from dataDir.
+// A missing state file yields an empty state.
+func loadState(dataDir string) (*updaterState, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, stateFileName))
+	if os.IsNotExist(err) {
+		return &updaterState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read updater state: %w", err)
+	}
+
+	var state updaterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode updater state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveState writes the updater state to config.DataDir, creating it with the configured DirMode.
+// The state file gets the configured FileMode without execute bits; when FileMode is unset, as for
+// callers that only know the data directory, an existing state file keeps its mode.
+// The state is written to a temporary file and renamed into place so a crash never leaves it truncated.
+func saveState(config UpdateConfig, state *updaterState) error {
+	dataDir := config.DataDir
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode updater state: %w", err)
+	}
+
+	if err := os.MkdirAll(dataDir, dirMode(config)); err != nil {
+		return fmt.Errorf("failed to create data directory %q: %w", dataDir, err)
+	}
+
+	path := filepath.Join(dataDir, stateFileName)
+	mode := fileMode(config) &^ 0111
+	if info, err := os.Stat(path); err == nil && config.FileMode == 0 {
+		mode = info.Mode().Perm()
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("failed to write updater state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write updater state: %w", err)
+	}
+	return nil
+}
+
+// updateState loads the state from config.DataDir, applies mutate and saves the result.
+func updateState(config UpdateConfig, mutate func(*updaterState)) error {
+	state, err := loadState(config.DataDir)
+	if err != nil {
+		return err
+	}
+	mutate(state)
+	return saveState(config, state)
+}
@@ -0,0 +1,81 @@
+package ghupdate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrStrictTransport is returned when StrictTransport is enabled and a download response is
+// inconsistent with what was requested, or no digest is available to verify the asset.
+// Such downloads are discarded and not retried.
+var ErrStrictTransport = errors.New("download rejected by strict transport checks")
+
+// contentRange is a parsed "bytes <first>-<last>/<total>" Content-Range header.
+// total is -1 if the server reported it as unknown ("*").
+type contentRange struct {
+	first, last, total int64
+}
+
+// parseContentRange parses the Content-Range header of a 206 response.
+// It returns false if the header is missing or malformed.
+func parseContentRange(header string) (contentRange, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return contentRange{}, false
+	}
+	span, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return contentRange{}, false
+	}
+	first, last, ok := strings.Cut(span, "-")
+	if !ok {
+		return contentRange{}, false
+	}
+
+	var r contentRange
+	var err1, err2, err3 error
+	r.first, err1 = strconv.ParseInt(first, 10, 64)
+	r.last, err2 = strconv.ParseInt(last, 10, 64)
+	r.total = -1
+	if total != "*" {
+		r.total, err3 = strconv.ParseInt(total, 10, 64)
+	}
+	if err1 != nil || err2 != nil || err3 != nil || r.first < 0 || r.last < r.first {
+		return contentRange{}, false
+	}
+	return r, true
+}
+
+// checkStrictResponse validates a download response under StrictTransport. The request asked for
+// bytes first through last of an asset of size bytes (last is -1 for an open-ended range, and size
+// is 0 if unknown). A full response must declare a Content-Length equal to size, and a partial
+// response must cover exactly the requested range of an asset of that size, with a matching
+// Content-Length.
+//
+// It returns an error wrapping ErrStrictTransport if the response is inconsistent.
+func checkStrictResponse(resp *http.Response, url string, first, last, size int64) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if size > 0 && resp.ContentLength != size {
+			return fmt.Errorf("%w: %q declared Content-Length %d, expected %d", ErrStrictTransport, url, resp.ContentLength, size)
+		}
+	case http.StatusPartialContent:
+		r, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			return fmt.Errorf("%w: %q sent a partial response with invalid Content-Range %q", ErrStrictTransport, url, resp.Header.Get("Content-Range"))
+		}
+		if last < 0 && size > 0 {
+			last = size - 1
+		}
+		if r.first != first || (last >= 0 && r.last != last) || (size > 0 && r.total != size) {
+			return fmt.Errorf("%w: %q sent range %d-%d/%d, requested %d-%d of %d bytes", ErrStrictTransport, url, r.first, r.last, r.total, first, last, size)
+		}
+		if resp.ContentLength != r.last-r.first+1 {
+			return fmt.Errorf("%w: %q declared Content-Length %d for a range of %d bytes", ErrStrictTransport, url, resp.ContentLength, r.last-r.first+1)
+		}
+	}
+	return nil
+}
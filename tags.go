@@ -0,0 +1,88 @@
+package ghupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// errNoReleases is returned when a repository has no published release in the requested channel.
+var errNoReleases = errors.New("no releases found")
+
+// gitHubTag represents a tag from the GitHub tags API.
+type gitHubTag struct {
+	Name string `json:"name"`
+}
+
+// releasesUnavailable reports whether err means the releases API has nothing to offer: the repository
+// has no (matching) release, or the releases endpoint is not found, e.g. on mirrors without it.
+func releasesUnavailable(err error) bool {
+	if errors.Is(err, errNoReleases) {
+		return true
+	}
+	var status *statusError
+	return errors.As(err, &status) && status.StatusCode == http.StatusNotFound
+}
+
+// fetchReleaseFromTags discovers the newest version of channel from the repository's tags, for
+// repositories whose releases API is disabled or empty. Stable releases are tags without a semantic
+// version prerelease part; other channels also accept prereleases named after them. The assets of the
+// chosen tag are probed at TagAssetURLTemplate (see probeRelease), so the release has no notes and its
+// assets carry no digest.
+//
+// It returns an error if the tags cannot be fetched or no tag belongs to the channel.
+func fetchReleaseFromTags(config UpdateConfig, channel string) (*GitHubRelease, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/tags?per_page=100", config.GitHubOwner, config.GitHubRepo)
+	var tags []gitHubTag
+	if err := fetchAPI(config, url, func(body []byte) error {
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return fmt.Errorf("failed to decode GitHub tags JSON: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	var newest string
+	for _, tag := range tags {
+		if tag.Name == "" || len(tag.Name) > maxTagNameLength || !tagInChannel(config, tag.Name, channel) {
+			continue
+		}
+		if newest == "" || compareVersions(config, tag.Name, newest) > 0 {
+			newest = tag.Name
+		}
+	}
+	if newest == "" {
+		return nil, fmt.Errorf("%w: no tags found in channel %q", errNoReleases, channel)
+	}
+
+	template := config.TagAssetURLTemplate
+	if template == "" {
+		template = githubDownloadURLTemplate
+	}
+	return probeRelease(config, newest, template)
+}
+
+// tagInChannel reports whether a tag is a version belonging to channel, following the rules of inChannel.
+// Tags that are not semantic versions are skipped unless a custom comparator is configured, in
+// which case they are considered stable. With IncludePrereleases, every prerelease is accepted.
+func tagInChannel(config UpdateConfig, tag, channel string) bool {
+	version := tag
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return hasCustomComparator(config) && channel == ChannelStable
+	}
+	if config.IncludePrereleases {
+		return true
+	}
+	if channel == ChannelStable {
+		return semver.Prerelease(version) == ""
+	}
+	return inChannel(&GitHubRelease{TagName: tag}, channel)
+}
@@ -0,0 +1,95 @@
+package ghupdate
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+)
+
+// UpdateToVersion stages the release tagged version (e.g., "v1.4.2") for installation with
+// ApplyUpdate, whether it is newer or older than CurrentVersion, to roll back to a previous version
+// or reproduce a bug on an older build. The newer-only check, the channel and downgrade protection
+// are bypassed, since the version was requested explicitly; the asset is verified like any other
+// update. In RedirectMode, and with TagsFallback if the tag has no release, the assets are probed
+// at their download URLs.
+//
+// It returns nil if version is already running, an error wrapping ErrReleaseYanked if the publisher
+// has yanked it, an error wrapping ErrUnsupportedPlatform if its metadata excludes this machine,
+// or an error if the release cannot be fetched, has no matching asset, or its
+// download, verification or staging fails.
+func UpdateToVersion(config UpdateConfig, version string) (*UpdateInfo, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("version is required")
+	}
+	if compareVersions(config, version, config.CurrentVersion) == 0 {
+		return nil, nil // Already running the requested version
+	}
+
+	release, err := fetchReleaseByTag(config, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", version, err)
+	}
+
+	yanked, err := fetchYankedReleases(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if entry := findYanked(config, yanked, release.TagName); entry != nil {
+		if entry.Reason != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrReleaseYanked, release.TagName, entry.Reason)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrReleaseYanked, release.TagName)
+	}
+	if err := checkReleaseMetadata(config, release); err != nil {
+		return nil, err
+	}
+	return prepareRelease(config, release)
+}
+
+// fetchReleaseByTag fetches the release tagged tag. With IncludeDrafts, a draft release for the tag
+// is looked up among the most recent releases, since drafts cannot be fetched by tag.
+func fetchReleaseByTag(config UpdateConfig, tag string) (*GitHubRelease, error) {
+	if config.RedirectMode {
+		return probeRelease(config, tag, githubDownloadURLTemplate)
+	}
+
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", config.GitHubOwner, config.GitHubRepo, url.PathEscape(tag))
+	var release *GitHubRelease
+	err := fetchAPI(config, apiURL, func(body []byte) error {
+		var err error
+		release, err = decodeRelease(bytes.NewReader(body))
+		return err
+	})
+	if err != nil && config.IncludeDrafts && releasesUnavailable(err) {
+		if draft, draftErr := fetchDraftByTag(config, tag); draftErr == nil && draft != nil {
+			return draft, nil
+		}
+	}
+	if err != nil && config.TagsFallback && releasesUnavailable(err) {
+		template := config.TagAssetURLTemplate
+		if template == "" {
+			template = githubDownloadURLTemplate
+		}
+		return probeRelease(config, tag, template)
+	}
+	return release, err
+}
+
+// fetchDraftByTag looks up the draft release for tag among the most recent releases.
+//
+// It returns nil if there is no such draft, or an error if the releases cannot be listed.
+func fetchDraftByTag(config UpdateConfig, tag string) (*GitHubRelease, error) {
+	releases, err := ListReleases(config, ListOptions{PerPage: maxReleasesPerPage})
+	if err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		if releases[i].Draft && releases[i].TagName == tag {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,52 @@
+package ghupdate
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sharedStoragePrefixes are Android locations backed by shared storage, which is always mounted noexec.
+var sharedStoragePrefixes = []string{"/sdcard", "/storage", "/mnt/sdcard"}
+
+// isTermux reports whether the process runs under Android, typically inside Termux.
+// Termux binaries are often plain linux/arm64 builds, so besides GOOS the Termux
+// environment variables and $PREFIX layout are consulted.
+func isTermux() bool {
+	if runtime.GOOS == "android" || os.Getenv("TERMUX_VERSION") != "" {
+		return true
+	}
+	return strings.Contains(os.Getenv("PREFIX"), "/com.termux/")
+}
+
+// termuxTempDir returns the Termux temporary directory, which lives in app-private, executable storage.
+func termuxTempDir() string {
+	if dir := os.Getenv("TMPDIR"); dir != "" {
+		return dir
+	}
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		return filepath.Join(prefix, "tmp")
+	}
+	return os.TempDir()
+}
+
+// onSharedStorage reports whether path lies on Android shared storage.
+func onSharedStorage(path string) bool {
+	for _, prefix := range sharedStoragePrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// stagingDir returns the directory in which the update executable is staged for a data directory.
+// This is the data directory itself, except on Android when it lies on shared storage: executables
+// cannot be run from there, so the update is staged in the Termux temporary directory instead.
+func stagingDir(dataDir string) string {
+	if isTermux() && onSharedStorage(dataDir) {
+		return filepath.Join(termuxTempDir(), "ghupdate")
+	}
+	return dataDir
+}
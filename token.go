@@ -0,0 +1,126 @@
+package ghupdate
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AuthScheme is the scheme used in the Authorization header sent to GitHub.
+type AuthScheme string
+
+const (
+	// AuthSchemeToken sends "Authorization: token <value>", the historical scheme for classic tokens.
+	AuthSchemeToken AuthScheme = "token"
+	// AuthSchemeBearer sends "Authorization: Bearer <value>", required for GitHub App installation
+	// tokens and recommended for fine-grained personal access tokens.
+	AuthSchemeBearer AuthScheme = "Bearer"
+)
+
+// Token is a GitHub credential returned by a TokenSource.
+type Token struct {
+	// Value is the secret token. An empty value means requests are sent unauthenticated.
+	Value string
+	// Scheme is the authorization scheme. If empty, AuthSchemeBearer is used for fine-grained
+	// personal access tokens (prefixed "github_pat_") and AuthSchemeToken for everything else.
+	Scheme AuthScheme
+}
+
+// TokenSource supplies GitHub credentials on demand. It is consulted before every request,
+// so long-running applications can rotate tokens without rebuilding their UpdateConfig and
+// without keeping the secret in a plain struct field.
+type TokenSource interface {
+	// Token returns the credential to use for the next request.
+	Token() (Token, error)
+}
+
+// TokenSourceFunc adapts an ordinary function to the TokenSource interface.
+type TokenSourceFunc func() (Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token() (Token, error) {
+	return f()
+}
+
+// StaticToken returns a TokenSource that always yields the given token value with the default scheme.
+func StaticToken(value string) TokenSource {
+	return TokenSourceFunc(func() (Token, error) {
+		return Token{Value: value}, nil
+	})
+}
+
+// EnvToken returns a TokenSource that reads the token from the named environment variable on every
+// request. An unset or empty variable results in unauthenticated requests.
+func EnvToken(name string) TokenSource {
+	return TokenSourceFunc(func() (Token, error) {
+		return Token{Value: os.Getenv(name)}, nil
+	})
+}
+
+// FileToken returns a TokenSource that reads the token from the file at path on every request,
+// trimming surrounding whitespace. This suits secrets mounted by container orchestrators, which
+// replace the file when the token is rotated.
+func FileToken(path string) TokenSource {
+	return TokenSourceFunc(func() (Token, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Token{}, fmt.Errorf("failed to read token file %q: %w", path, err)
+		}
+		return Token{Value: strings.TrimSpace(string(data))}, nil
+	})
+}
+
+// KeystoreToken returns a TokenSource that reads the token from the operating system keystore under
+// the given service and account names, using the same backends as OSKeystoreKey. The token must
+// have been stored beforehand (e.g., with `secret-tool store` or `security add-generic-password`).
+//
+// The returned source yields an error wrapping ErrKeystoreUnavailable where no keystore is supported.
+func KeystoreToken(service, account string) TokenSource {
+	return TokenSourceFunc(func() (Token, error) {
+		secret, found, err := keystoreGet(service, account)
+		if err != nil {
+			return Token{}, err
+		}
+		if !found {
+			return Token{}, fmt.Errorf("no token stored in the keystore for %s/%s", service, account)
+		}
+		return Token{Value: strings.TrimSpace(secret)}, nil
+	})
+}
+
+// resolveToken returns the credential to use for a request: the one from config.TokenSource if set,
+// otherwise config.GitHubToken.
+func resolveToken(config UpdateConfig) (Token, error) {
+	if config.TokenSource == nil {
+		return Token{Value: config.GitHubToken}, nil
+	}
+	token, err := config.TokenSource.Token()
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to obtain GitHub token: %w", err)
+	}
+	return token, nil
+}
+
+// setAuthHeader adds the Authorization header for the configured credential to req, if any.
+//
+// It returns an error if the TokenSource fails.
+func setAuthHeader(config UpdateConfig, req *http.Request) error {
+	token, err := resolveToken(config)
+	if err != nil {
+		return err
+	}
+	if token.Value == "" {
+		return nil
+	}
+
+	scheme := token.Scheme
+	if scheme == "" {
+		scheme = AuthSchemeToken
+		if strings.HasPrefix(token.Value, "github_pat_") {
+			scheme = AuthSchemeBearer
+		}
+	}
+	req.Header.Set("Authorization", string(scheme)+" "+token.Value)
+	return nil
+}
@@ -0,0 +1,612 @@
+package ghupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// tufMaxMetadataSize caps the size of any TUF metadata document fetched from the repository.
+	tufMaxMetadataSize = 10 << 20
+	// tufMaxRootRotations bounds the number of root versions walked in a single update.
+	tufMaxRootRotations = 256
+)
+
+// tufSigned is the envelope shared by all TUF metadata documents.
+type tufSigned struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []struct {
+		KeyID string `json:"keyid"`
+		Sig   string `json:"sig"`
+	} `json:"signatures"`
+}
+
+// tufKey is a public key listed in root metadata.
+type tufKey struct {
+	KeyType string `json:"keytype"`
+	Scheme  string `json:"scheme"`
+	KeyVal  struct {
+		Public string `json:"public"`
+	} `json:"keyval"`
+}
+
+// tufRole lists the keys trusted for a role and how many of them must sign.
+type tufRole struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// tufRoot is the signed portion of root.json.
+type tufRoot struct {
+	Type               string             `json:"_type"`
+	Version            int64              `json:"version"`
+	Expires            time.Time          `json:"expires"`
+	ConsistentSnapshot bool               `json:"consistent_snapshot"`
+	Keys               map[string]tufKey  `json:"keys"`
+	Roles              map[string]tufRole `json:"roles"`
+}
+
+// tufMetaFile describes another metadata file referenced by timestamp or snapshot metadata.
+type tufMetaFile struct {
+	Version int64             `json:"version"`
+	Length  int64             `json:"length,omitempty"`
+	Hashes  map[string]string `json:"hashes,omitempty"`
+}
+
+// tufTimestamp is the signed portion of timestamp.json.
+type tufTimestamp struct {
+	Type    string                 `json:"_type"`
+	Version int64                  `json:"version"`
+	Expires time.Time              `json:"expires"`
+	Meta    map[string]tufMetaFile `json:"meta"`
+}
+
+// tufSnapshot is the signed portion of snapshot.json.
+type tufSnapshot struct {
+	Type    string                 `json:"_type"`
+	Version int64                  `json:"version"`
+	Expires time.Time              `json:"expires"`
+	Meta    map[string]tufMetaFile `json:"meta"`
+}
+
+// tufTargets is the signed portion of targets.json.
+type tufTargets struct {
+	Type    string               `json:"_type"`
+	Version int64                `json:"version"`
+	Expires time.Time            `json:"expires"`
+	Targets map[string]tufTarget `json:"targets"`
+}
+
+// tufTarget describes a single target file: its expected length, hashes and custom metadata.
+// ghupdate reads the "version" key from Custom to authenticate the release version of a target.
+type tufTarget struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+	Custom map[string]any    `json:"custom,omitempty"`
+}
+
+// tufClient performs a TUF metadata update against a remote repository, persisting trusted
+// metadata in a local directory so rollback attacks can be detected across runs.
+type tufClient struct {
+	baseURL  string
+	localDir string
+	config   UpdateConfig
+	now      time.Time
+	ctx      context.Context
+}
+
+// fetchTUFTargets runs the TUF client workflow (root rotation, timestamp, snapshot, targets)
+// against config.TUFRepositoryURL and returns the verified target map.
+// Only top-level targets are supported; delegated targets roles are not consulted.
+//
+// It returns an error if any metadata is missing, expired, improperly signed, or older than
+// metadata previously trusted by this installation.
+func fetchTUFTargets(ctx context.Context, config UpdateConfig) (map[string]tufTarget, error) {
+	client := &tufClient{
+		baseURL:  strings.TrimRight(config.TUFRepositoryURL, "/"),
+		localDir: filepath.Join(config.DataDir, "tuf"),
+		config:   config,
+		now:      time.Now(),
+		ctx:      ctx,
+	}
+
+	if err := os.MkdirAll(client.localDir, dirMode(config)); err != nil {
+		return nil, fmt.Errorf("failed to create TUF metadata directory: %w", err)
+	}
+
+	root, err := client.updateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := client.updateTimestamp(root)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := client.updateSnapshot(root, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := client.updateTargets(root, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return targets.Targets, nil
+}
+
+// verifyTUFTarget checks that the file at path matches the length and hashes of a TUF target
+// and, when the target declares a custom "version", that it matches the release version.
+func verifyTUFTarget(target tufTarget, path, version string) error {
+	if declared, ok := target.Custom["version"].(string); ok {
+		if strings.TrimPrefix(declared, "v") != strings.TrimPrefix(version, "v") {
+			return fmt.Errorf("TUF targets metadata authorizes version %s, but release is %s", declared, version)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %q for TUF verification: %w", path, err)
+	}
+	if int64(len(data)) != target.Length {
+		return fmt.Errorf("TUF target length mismatch: expected %d bytes, got %d", target.Length, len(data))
+	}
+	return verifyTUFHashes(data, target.Hashes)
+}
+
+// updateRoot loads the trusted root and walks forward through newer root versions published by
+// the repository, verifying each against both the previous and the new root keys.
+func (c *tufClient) updateRoot() (*tufRoot, error) {
+	trusted, err := os.ReadFile(filepath.Join(c.localDir, "root.json"))
+	if os.IsNotExist(err) {
+		trusted = c.config.TUFRootMetadata
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read trusted TUF root: %w", err)
+	}
+	if len(trusted) == 0 {
+		return nil, fmt.Errorf("TUFRootMetadata is required to bootstrap TUF verification")
+	}
+
+	root, err := parseTUFRoot(trusted)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted TUF root: %w", err)
+	}
+
+	for i := 0; i < tufMaxRootRotations; i++ {
+		next := strconv.FormatInt(root.Version+1, 10) + ".root.json"
+		data, found, err := c.fetch(next)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			break
+		}
+
+		// A new root must be signed by a threshold of both the old and the new root keys.
+		if err := verifyTUFSignatures(data, root, "root"); err != nil {
+			return nil, fmt.Errorf("%s: %w", next, err)
+		}
+		newRoot, err := parseTUFRoot(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", next, err)
+		}
+		if err := verifyTUFSignatures(data, newRoot, "root"); err != nil {
+			return nil, fmt.Errorf("%s: %w", next, err)
+		}
+		if newRoot.Version != root.Version+1 {
+			return nil, fmt.Errorf("%s: expected root version %d, got %d", next, root.Version+1, newRoot.Version)
+		}
+
+		root = newRoot
+		trusted = data
+	}
+
+	if err := c.persist("root.json", trusted); err != nil {
+		return nil, err
+	}
+
+	if c.now.After(root.Expires) {
+		return nil, fmt.Errorf("TUF root metadata expired at %s", root.Expires.Format(time.RFC3339))
+	}
+	return root, nil
+}
+
+// updateTimestamp fetches and verifies timestamp.json.
+func (c *tufClient) updateTimestamp(root *tufRoot) (*tufTimestamp, error) {
+	data, found, err := c.fetch("timestamp.json")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("timestamp.json not found in TUF repository")
+	}
+
+	var timestamp tufTimestamp
+	if err := c.verifyAndDecode("timestamp", data, root, &timestamp); err != nil {
+		return nil, err
+	}
+
+	var trusted tufTimestamp
+	if c.loadTrusted("timestamp.json", &trusted) {
+		if timestamp.Version < trusted.Version {
+			return nil, fmt.Errorf("TUF timestamp rollback: version %d is older than trusted version %d", timestamp.Version, trusted.Version)
+		}
+		if timestamp.Meta["snapshot.json"].Version < trusted.Meta["snapshot.json"].Version {
+			return nil, fmt.Errorf("TUF snapshot rollback detected in timestamp metadata")
+		}
+	}
+
+	if c.now.After(timestamp.Expires) {
+		return nil, fmt.Errorf("TUF timestamp metadata expired at %s", timestamp.Expires.Format(time.RFC3339))
+	}
+	if err := c.persist("timestamp.json", data); err != nil {
+		return nil, err
+	}
+	return &timestamp, nil
+}
+
+// updateSnapshot fetches snapshot.json, checks it against the timestamp and verifies it.
+func (c *tufClient) updateSnapshot(root *tufRoot, timestamp *tufTimestamp) (*tufSnapshot, error) {
+	meta, ok := timestamp.Meta["snapshot.json"]
+	if !ok {
+		return nil, fmt.Errorf("TUF timestamp does not reference snapshot.json")
+	}
+
+	name := "snapshot.json"
+	if root.ConsistentSnapshot {
+		name = strconv.FormatInt(meta.Version, 10) + "." + name
+	}
+	data, found, err := c.fetch(name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found in TUF repository", name)
+	}
+	if err := verifyTUFMetaFile(data, meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var snapshot tufSnapshot
+	if err := c.verifyAndDecode("snapshot", data, root, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != meta.Version {
+		return nil, fmt.Errorf("TUF snapshot version %d does not match timestamp version %d", snapshot.Version, meta.Version)
+	}
+
+	var trusted tufSnapshot
+	if c.loadTrusted("snapshot.json", &trusted) {
+		for file, old := range trusted.Meta {
+			if current, ok := snapshot.Meta[file]; !ok || current.Version < old.Version {
+				return nil, fmt.Errorf("TUF snapshot rollback detected for %s", file)
+			}
+		}
+	}
+
+	if c.now.After(snapshot.Expires) {
+		return nil, fmt.Errorf("TUF snapshot metadata expired at %s", snapshot.Expires.Format(time.RFC3339))
+	}
+	if err := c.persist("snapshot.json", data); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// updateTargets fetches targets.json, checks it against the snapshot and verifies it.
+func (c *tufClient) updateTargets(root *tufRoot, snapshot *tufSnapshot) (*tufTargets, error) {
+	meta, ok := snapshot.Meta["targets.json"]
+	if !ok {
+		return nil, fmt.Errorf("TUF snapshot does not reference targets.json")
+	}
+
+	name := "targets.json"
+	if root.ConsistentSnapshot {
+		name = strconv.FormatInt(meta.Version, 10) + "." + name
+	}
+	data, found, err := c.fetch(name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found in TUF repository", name)
+	}
+	if err := verifyTUFMetaFile(data, meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var targets tufTargets
+	if err := c.verifyAndDecode("targets", data, root, &targets); err != nil {
+		return nil, err
+	}
+	if targets.Version != meta.Version {
+		return nil, fmt.Errorf("TUF targets version %d does not match snapshot version %d", targets.Version, meta.Version)
+	}
+
+	var trusted tufTargets
+	if c.loadTrusted("targets.json", &trusted) && targets.Version < trusted.Version {
+		return nil, fmt.Errorf("TUF targets rollback: version %d is older than trusted version %d", targets.Version, trusted.Version)
+	}
+
+	if c.now.After(targets.Expires) {
+		return nil, fmt.Errorf("TUF targets metadata expired at %s", targets.Expires.Format(time.RFC3339))
+	}
+	if err := c.persist("targets.json", data); err != nil {
+		return nil, err
+	}
+	return &targets, nil
+}
+
+// verifyAndDecode verifies the signatures on a metadata document for the given role and
+// decodes its signed portion into v, checking the declared _type.
+func (c *tufClient) verifyAndDecode(role string, data []byte, root *tufRoot, v any) error {
+	if err := verifyTUFSignatures(data, root, role); err != nil {
+		return fmt.Errorf("%s.json: %w", role, err)
+	}
+
+	var envelope tufSigned
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode %s.json: %w", role, err)
+	}
+	if err := json.Unmarshal(envelope.Signed, v); err != nil {
+		return fmt.Errorf("failed to decode %s.json: %w", role, err)
+	}
+
+	var header struct {
+		Type string `json:"_type"`
+	}
+	json.Unmarshal(envelope.Signed, &header)
+	if header.Type != role {
+		return fmt.Errorf("%s.json has unexpected type %q", role, header.Type)
+	}
+	return nil
+}
+
+// fetch downloads a metadata file from the repository. A 404 response is reported as not found.
+func (c *tufClient) fetch(name string) ([]byte, bool, error) {
+	url := c.baseURL + "/" + name
+
+	req, err := http.NewRequestWithContext(c.ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create HTTP request for %q: %w", url, err)
+	}
+	setUserAgent(c.config, req)
+
+	client := httpClient(c.config, checkTimeout(c.config))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch TUF metadata %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("TUF repository returned status %d for %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, tufMaxMetadataSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read TUF metadata %q: %w", url, err)
+	}
+	if len(data) > tufMaxMetadataSize {
+		return nil, false, fmt.Errorf("TUF metadata %q exceeds %d bytes", url, tufMaxMetadataSize)
+	}
+	return data, true, nil
+}
+
+// loadTrusted decodes the signed portion of a previously trusted metadata file.
+// It returns false if no trusted copy exists or it cannot be decoded.
+func (c *tufClient) loadTrusted(name string, v any) bool {
+	data, err := os.ReadFile(filepath.Join(c.localDir, name))
+	if err != nil {
+		return false
+	}
+	var envelope tufSigned
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return false
+	}
+	return json.Unmarshal(envelope.Signed, v) == nil
+}
+
+// persist stores verified metadata as the new trusted copy, with the configured file mode less the
+// execute bits.
+func (c *tufClient) persist(name string, data []byte) error {
+	if err := os.WriteFile(filepath.Join(c.localDir, name), data, fileMode(c.config)&^0111); err != nil {
+		return fmt.Errorf("failed to persist TUF metadata %s: %w", name, err)
+	}
+	return nil
+}
+
+// parseTUFRoot decodes the signed portion of a root.json document.
+func parseTUFRoot(data []byte) (*tufRoot, error) {
+	var envelope tufSigned
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode root metadata: %w", err)
+	}
+	var root tufRoot
+	if err := json.Unmarshal(envelope.Signed, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode root metadata: %w", err)
+	}
+	if root.Type != "root" {
+		return nil, fmt.Errorf("root metadata has unexpected type %q", root.Type)
+	}
+	return &root, nil
+}
+
+// verifyTUFSignatures checks that the document is signed by at least the threshold of distinct
+// keys that root trusts for role. Only ed25519 keys are supported.
+func verifyTUFSignatures(data []byte, root *tufRoot, role string) error {
+	roleInfo, ok := root.Roles[role]
+	if !ok || roleInfo.Threshold < 1 {
+		return fmt.Errorf("root metadata does not define a valid %q role", role)
+	}
+
+	var envelope tufSigned
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode signed metadata: %w", err)
+	}
+	canonical, err := canonicalJSON(envelope.Signed)
+	if err != nil {
+		return err
+	}
+
+	allowed := make(map[string]bool, len(roleInfo.KeyIDs))
+	for _, id := range roleInfo.KeyIDs {
+		allowed[id] = true
+	}
+
+	valid := make(map[string]bool)
+	for _, sig := range envelope.Signatures {
+		if !allowed[sig.KeyID] || valid[sig.KeyID] {
+			continue
+		}
+		key, ok := root.Keys[sig.KeyID]
+		if !ok || key.KeyType != "ed25519" {
+			continue
+		}
+		pub, err := hex.DecodeString(key.KeyVal.Public)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			continue
+		}
+		rawSig, err := hex.DecodeString(sig.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(ed25519.PublicKey(pub), canonical, rawSig) {
+			valid[sig.KeyID] = true
+		}
+	}
+
+	if len(valid) < roleInfo.Threshold {
+		return fmt.Errorf("signature threshold not met for role %q: %d of %d", role, len(valid), roleInfo.Threshold)
+	}
+	return nil
+}
+
+// verifyTUFMetaFile checks a fetched metadata file against the length and hashes declared for it.
+func verifyTUFMetaFile(data []byte, meta tufMetaFile) error {
+	if meta.Length > 0 && int64(len(data)) != meta.Length {
+		return fmt.Errorf("length mismatch: expected %d bytes, got %d", meta.Length, len(data))
+	}
+	if len(meta.Hashes) > 0 {
+		return verifyTUFHashes(data, meta.Hashes)
+	}
+	return nil
+}
+
+// verifyTUFHashes checks data against every supported hash in hashes. At least one supported
+// algorithm (sha256 or sha512) must be present.
+func verifyTUFHashes(data []byte, hashes map[string]string) error {
+	checked := 0
+	for algorithm, expected := range hashes {
+		var h hash.Hash
+		switch algorithm {
+		case "sha256":
+			h = sha256.New()
+		case "sha512":
+			h = sha512.New()
+		default:
+			continue
+		}
+		h.Write(data)
+		if hex.EncodeToString(h.Sum(nil)) != strings.ToLower(expected) {
+			return fmt.Errorf("%s hash mismatch", algorithm)
+		}
+		checked++
+	}
+	if checked == 0 {
+		return fmt.Errorf("no supported hash algorithm (sha256, sha512) declared")
+	}
+	return nil
+}
+
+// canonicalJSON re-encodes a JSON document in the OLPC canonical form used for TUF signatures:
+// object keys sorted, no insignificant whitespace, and only '"' and '\' escaped in strings.
+func canonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata for canonicalization: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCanonicalJSON writes a decoded JSON value in canonical form.
+func writeCanonicalJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return fmt.Errorf("canonical JSON does not allow non-integer number %s", v)
+		}
+		buf.WriteString(v.String())
+	case string:
+		buf.WriteByte('"')
+		buf.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v))
+		buf.WriteByte('"')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", value)
+	}
+	return nil
+}
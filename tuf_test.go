@@ -0,0 +1,276 @@
+package ghupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tufTestKey is an ed25519 key signing TUF metadata in tests.
+type tufTestKey struct {
+	id   string
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTUFTestKey(t *testing.T) tufTestKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(pub)
+	return tufTestKey{id: hex.EncodeToString(sum[:]), pub: pub, priv: priv}
+}
+
+// tufKey returns the root metadata entry of the key.
+func (k tufTestKey) tufKey() tufKey {
+	key := tufKey{KeyType: "ed25519", Scheme: "ed25519"}
+	key.KeyVal.Public = hex.EncodeToString(k.pub)
+	return key
+}
+
+// signTUF returns the metadata document holding signed, signed by keys.
+func signTUF(t *testing.T, signed any, keys ...tufTestKey) []byte {
+	t.Helper()
+	raw, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures := []map[string]string{}
+	for _, key := range keys {
+		signatures = append(signatures, map[string]string{
+			"keyid": key.id,
+			"sig":   hex.EncodeToString(ed25519.Sign(key.priv, canonical)),
+		})
+	}
+	data, err := json.Marshal(map[string]any{"signed": json.RawMessage(raw), "signatures": signatures})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifyTUFSignaturesThreshold(t *testing.T) {
+	k1, k2, k3, outsider := newTUFTestKey(t), newTUFTestKey(t), newTUFTestKey(t), newTUFTestKey(t)
+	root := &tufRoot{
+		Type:  "root",
+		Keys:  map[string]tufKey{k1.id: k1.tufKey(), k2.id: k2.tufKey(), k3.id: k3.tufKey(), outsider.id: outsider.tufKey()},
+		Roles: map[string]tufRole{"targets": {KeyIDs: []string{k1.id, k2.id, k3.id}, Threshold: 2}},
+	}
+	signed := map[string]any{"_type": "targets", "version": 1}
+
+	// A signature by k2 over other content, presented under k2's key ID
+	forged := signTUF(t, map[string]any{"_type": "targets", "version": 2}, k2)
+	var forgedEnvelope tufSigned
+	json.Unmarshal(forged, &forgedEnvelope)
+	var badSig tufSigned
+	json.Unmarshal(signTUF(t, signed, k1), &badSig)
+	badSig.Signatures = append(badSig.Signatures, forgedEnvelope.Signatures...)
+	withBadSig, _ := json.Marshal(badSig)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"threshold met", signTUF(t, signed, k1, k2), false},
+		{"all keys", signTUF(t, signed, k1, k2, k3), false},
+		{"one key", signTUF(t, signed, k1), true},
+		{"unsigned", signTUF(t, signed), true},
+		{"same key twice", signTUF(t, signed, k1, k1), true},
+		{"key not trusted for role", signTUF(t, signed, k1, outsider), true},
+		{"invalid signature", withBadSig, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTUFSignatures(tt.data, root, "targets")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyTUFSignatures = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := verifyTUFSignatures(signTUF(t, signed, k1, k2), root, "snapshot"); err == nil {
+		t.Error("verifyTUFSignatures for a role missing from root succeeded, want an error")
+	}
+}
+
+// tufTestRepo is a TUF repository with one key per top-level role, served over HTTP.
+type tufTestRepo struct {
+	root, timestamp, snapshot, targets tufTestKey
+
+	rootExpires, timestampExpires, snapshotExpires, targetsExpires time.Time
+	targetFiles                                                    map[string]tufTarget
+	// targetsSigners sign targets.json in place of the targets key, if set.
+	targetsSigners []tufTestKey
+}
+
+func newTUFTestRepo(t *testing.T) *tufTestRepo {
+	expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	return &tufTestRepo{
+		root:             newTUFTestKey(t),
+		timestamp:        newTUFTestKey(t),
+		snapshot:         newTUFTestKey(t),
+		targets:          newTUFTestKey(t),
+		rootExpires:      expires,
+		timestampExpires: expires,
+		snapshotExpires:  expires,
+		targetsExpires:   expires,
+		targetFiles:      map[string]tufTarget{},
+	}
+}
+
+// files returns the signed metadata of the repository, by file name.
+func (r *tufTestRepo) files(t *testing.T) map[string][]byte {
+	role := func(key tufTestKey) tufRole { return tufRole{KeyIDs: []string{key.id}, Threshold: 1} }
+	root := signTUF(t, tufRoot{
+		Type:    "root",
+		Version: 1,
+		Expires: r.rootExpires,
+		Keys: map[string]tufKey{
+			r.root.id: r.root.tufKey(), r.timestamp.id: r.timestamp.tufKey(),
+			r.snapshot.id: r.snapshot.tufKey(), r.targets.id: r.targets.tufKey(),
+		},
+		Roles: map[string]tufRole{
+			"root": role(r.root), "timestamp": role(r.timestamp),
+			"snapshot": role(r.snapshot), "targets": role(r.targets),
+		},
+	}, r.root)
+	targetsSigners := r.targetsSigners
+	if targetsSigners == nil {
+		targetsSigners = []tufTestKey{r.targets}
+	}
+	targets := signTUF(t, tufTargets{Type: "targets", Version: 1, Expires: r.targetsExpires, Targets: r.targetFiles}, targetsSigners...)
+	snapshot := signTUF(t, tufSnapshot{
+		Type: "snapshot", Version: 1, Expires: r.snapshotExpires,
+		Meta: map[string]tufMetaFile{"targets.json": {Version: 1}},
+	}, r.snapshot)
+	sum := sha256.Sum256(snapshot)
+	timestamp := signTUF(t, tufTimestamp{
+		Type: "timestamp", Version: 1, Expires: r.timestampExpires,
+		Meta: map[string]tufMetaFile{"snapshot.json": {
+			Version: 1, Length: int64(len(snapshot)), Hashes: map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		}},
+	}, r.timestamp)
+	return map[string][]byte{"root.json": root, "timestamp.json": timestamp, "snapshot.json": snapshot, "targets.json": targets}
+}
+
+// fetch runs the TUF client workflow against the repository, bootstrapped with its root.
+func (r *tufTestRepo) fetch(t *testing.T) (map[string]tufTarget, error) {
+	t.Helper()
+	files := r.files(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, "/")
+		if name == "root.json" || files[name] == nil {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(files[name])
+	}))
+	defer server.Close()
+
+	config := UpdateConfig{TUFRepositoryURL: server.URL, TUFRootMetadata: files["root.json"], DataDir: t.TempDir()}
+	return fetchTUFTargets(context.Background(), config)
+}
+
+func TestFetchTUFTargets(t *testing.T) {
+	repo := newTUFTestRepo(t)
+	repo.targetFiles["myapp-linux-amd64"] = tufTarget{Length: 5, Hashes: map[string]string{"sha256": "00"}}
+	targets, err := repo.fetch(t)
+	if err != nil {
+		t.Fatalf("fetchTUFTargets: %v", err)
+	}
+	if _, ok := targets["myapp-linux-amd64"]; !ok || len(targets) != 1 {
+		t.Errorf("fetchTUFTargets = %v, want the myapp-linux-amd64 target", targets)
+	}
+}
+
+func TestFetchTUFTargetsRejectsExpiredMetadata(t *testing.T) {
+	past := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	tests := []struct {
+		name   string
+		expire func(*tufTestRepo)
+		want   string
+	}{
+		{"root", func(r *tufTestRepo) { r.rootExpires = past }, "TUF root metadata expired"},
+		{"timestamp", func(r *tufTestRepo) { r.timestampExpires = past }, "TUF timestamp metadata expired"},
+		{"snapshot", func(r *tufTestRepo) { r.snapshotExpires = past }, "TUF snapshot metadata expired"},
+		{"targets", func(r *tufTestRepo) { r.targetsExpires = past }, "TUF targets metadata expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTUFTestRepo(t)
+			tt.expire(repo)
+			if _, err := repo.fetch(t); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("fetchTUFTargets = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTUFTargetsRejectsWrongSigner(t *testing.T) {
+	repo := newTUFTestRepo(t)
+	// The snapshot key is not trusted for the targets role
+	repo.targetsSigners = []tufTestKey{repo.snapshot}
+	if _, err := repo.fetch(t); err == nil || !strings.Contains(err.Error(), "signature threshold not met") {
+		t.Errorf("fetchTUFTargets = %v, want the targets signature threshold not met", err)
+	}
+}
+
+func TestVerifyTUFTarget(t *testing.T) {
+	content := []byte("myapp v1.2.3")
+	sum := sha256.Sum256(content)
+	path := filepath.Join(t.TempDir(), "myapp")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := tufTarget{
+		Length: int64(len(content)),
+		Hashes: map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		Custom: map[string]any{"version": "v1.2.3"},
+	}
+	if err := verifyTUFTarget(target, path, "1.2.3"); err != nil {
+		t.Errorf("verifyTUFTarget: %v", err)
+	}
+
+	tampered := []byte("myapp v1.2.4")
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTUFTarget(target, path, "v1.2.3"); err == nil || !strings.Contains(err.Error(), "sha256 hash mismatch") {
+		t.Errorf("verifyTUFTarget of a file with another hash = %v, want a sha256 hash mismatch", err)
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTUFTarget(target, path, "v1.2.3"); err == nil || !strings.Contains(err.Error(), "length mismatch") {
+		t.Errorf("verifyTUFTarget of a longer file = %v, want a length mismatch", err)
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTUFTarget(target, path, "v1.3.0"); err == nil {
+		t.Error("verifyTUFTarget of another release version succeeded, want an error")
+	}
+	target.Hashes = map[string]string{"md5": "00"}
+	if err := verifyTUFTarget(target, path, "v1.2.3"); err == nil {
+		t.Error("verifyTUFTarget without a supported hash succeeded, want an error")
+	}
+}
@@ -0,0 +1,330 @@
+// Package tui provides an interactive terminal update flow for applications using ghupdate,
+// suitable for a `myapp upgrade` command, built on bubbletea.
+//
+// Run checks for and downloads an update with a progress bar, shows the version change and
+// scrollable release notes, and asks whether to install now, skip the release, or be reminded later.
+//
+// The package is a module of its own, so that bubbletea and its dependencies are only added to
+// applications that import it rather than to every user of ghupdate.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asaidimu/ghupdate"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Action is the choice made by the user.
+type Action int
+
+const (
+	// ActionNone means no update was available.
+	ActionNone Action = iota
+	// ActionInstall means the user chose to install the update.
+	ActionInstall
+	// ActionSkip means the user chose to skip this release.
+	ActionSkip
+	// ActionSnooze means the user asked to be reminded later, or quit before a choice was made.
+	ActionSnooze
+)
+
+// String returns the name of the action.
+func (a Action) String() string {
+	switch a {
+	case ActionInstall:
+		return "install"
+	case ActionSkip:
+		return "skip"
+	case ActionSnooze:
+		return "snooze"
+	default:
+		return "none"
+	}
+}
+
+// ErrCancelled is returned by Run when the user quits while the update is being checked for or downloaded.
+var ErrCancelled = errors.New("update cancelled")
+
+const (
+	// defaultSnooze is how long a snoozed release is deferred when Options.SnoozeFor is zero.
+	defaultSnooze = 24 * time.Hour
+	// defaultWidth and defaultHeight size the interface until the terminal reports its size.
+	defaultWidth  = 80
+	defaultHeight = 24
+	// chromeHeight is the number of lines around the release notes: header, blank lines and help.
+	chromeHeight = 5
+	// maxProgressWidth is the widest the progress bar is drawn.
+	maxProgressWidth = 60
+)
+
+// Options customizes Run.
+type Options struct {
+	// In is read for user input. If nil, os.Stdin is used.
+	In io.Reader
+	// Out receives all output. If nil, os.Stdout is used.
+	Out io.Writer
+	// AppName is shown in the header. If empty, the repository name is used.
+	AppName string
+	// SnoozeFor is how long the release is deferred with ghupdate.DeferUpdate when the user asks to
+	// be reminded later. If zero, 24 hours is used.
+	SnoozeFor time.Duration
+}
+
+// Run checks for an update with config, showing a progress bar while it is downloaded, then presents
+// the version change and scrollable release notes and asks the user what to do.
+//
+// If the user chooses to install, ghupdate.ApplyUpdate is called; like ApplyUpdate, Run does not
+// return when the update is launched successfully. Snoozing defers the release with
+// ghupdate.DeferUpdate for Options.SnoozeFor, so that it is not offered again until then. Skipping
+// is only reported: the caller decides how to remember it (e.g., with its own settings).
+//
+// It returns the chosen action and the update information, or an error if checking, downloading
+// or applying the update fails, or ErrCancelled if the user quits before a choice can be made.
+// ActionNone with nil info means the application is up to date.
+func Run(config ghupdate.UpdateConfig, opts Options) (Action, *ghupdate.UpdateInfo, error) {
+	in, out := opts.In, opts.Out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	name := opts.AppName
+	if name == "" {
+		name = config.GitHubRepo
+	}
+	snooze := opts.SnoozeFor
+	if snooze <= 0 {
+		snooze = defaultSnooze
+	}
+
+	// Download progress is reported from the goroutine checking for the update
+	var program *tea.Program
+	next := config.Progress
+	config.Progress = func(downloaded, total int64) {
+		program.Send(progressMsg{downloaded: downloaded, total: total})
+		if next != nil {
+			next(downloaded, total)
+		}
+	}
+
+	program = tea.NewProgram(newModel(config, name), tea.WithInput(in), tea.WithOutput(out), tea.WithMouseCellMotion())
+	final, err := program.Run()
+	if err != nil {
+		return ActionNone, nil, fmt.Errorf("failed to run update interface: %w", err)
+	}
+	m := final.(model)
+	if m.err != nil {
+		return ActionNone, nil, m.err
+	}
+	if m.info == nil {
+		fmt.Fprintf(out, "%s %s is up to date.\n", name, config.CurrentVersion)
+		return ActionNone, nil, nil
+	}
+
+	switch m.action {
+	case ActionSnooze:
+		if config.DataDir != "" {
+			until := time.Now().Add(snooze)
+			if err := ghupdate.DeferUpdate(config.DataDir, m.info.LatestVersion, until); err != nil {
+				return ActionSnooze, m.info, err
+			}
+			fmt.Fprintf(out, "Update %s snoozed until %s.\n", m.info.LatestVersion, until.Format(time.DateTime))
+			return ActionSnooze, m.info, nil
+		}
+		fmt.Fprintf(out, "Update %s: %s.\n", m.info.LatestVersion, m.action)
+		return ActionSnooze, m.info, nil
+	case ActionSkip:
+		fmt.Fprintf(out, "Update %s: %s.\n", m.info.LatestVersion, m.action)
+		return ActionSkip, m.info, nil
+	}
+
+	fmt.Fprintf(out, "Installing %s...\n", m.info.LatestVersion)
+	if err := ghupdate.ApplyUpdate(config); err != nil {
+		if errors.Is(err, ghupdate.ErrUpdatedInProcess) {
+			fmt.Fprintf(out, "%s %s installed. Restart %s to use it.\n", name, m.info.LatestVersion, name)
+			return ActionInstall, m.info, nil
+		}
+		return ActionInstall, m.info, err
+	}
+	return ActionInstall, m.info, nil
+}
+
+// progressMsg reports download progress.
+type progressMsg struct {
+	downloaded, total int64
+}
+
+// checkedMsg carries the result of ghupdate.CheckAndPrepareUpdate.
+type checkedMsg struct {
+	info *ghupdate.UpdateInfo
+	err  error
+}
+
+// model is the bubbletea model of the update flow: checking (with download progress) until the
+// update is prepared, then choosing an action while the release notes can be scrolled.
+type model struct {
+	config ghupdate.UpdateConfig
+	name   string
+
+	width, height int
+	bar           progress.Model
+	downloaded    int64
+	total         int64
+
+	checked bool
+	info    *ghupdate.UpdateInfo
+	notes   viewport.Model
+	action  Action
+	err     error
+}
+
+// newModel returns the model of the update flow for config, naming the application name.
+func newModel(config ghupdate.UpdateConfig, name string) model {
+	m := model{
+		config: config,
+		name:   name,
+		width:  defaultWidth,
+		height: defaultHeight,
+		bar:    progress.New(progress.WithDefaultGradient()),
+		notes:  viewport.New(defaultWidth, defaultHeight-chromeHeight),
+	}
+	m.resize()
+	return m
+}
+
+// Init starts checking for the update.
+func (m model) Init() tea.Cmd {
+	config := m.config
+	return func() tea.Msg {
+		info, err := ghupdate.CheckAndPrepareUpdate(config)
+		return checkedMsg{info: info, err: err}
+	}
+}
+
+// Update handles terminal, progress and check messages.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+
+	case progressMsg:
+		m.downloaded, m.total = msg.downloaded, msg.total
+		return m, nil
+
+	case checkedMsg:
+		m.checked = true
+		m.info, m.err = msg.info, msg.err
+		if m.err != nil || m.info == nil {
+			return m, tea.Quit
+		}
+		m.setNotes()
+		return m, nil
+
+	case tea.KeyMsg:
+		if !m.checked || m.info == nil {
+			if msg.Type == tea.KeyCtrlC || msg.String() == "q" || msg.Type == tea.KeyEsc {
+				m.err = ErrCancelled
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "i", "y":
+			m.action = ActionInstall
+			return m, tea.Quit
+		case "s":
+			m.action = ActionSkip
+			return m, tea.Quit
+		case "l", "q", "esc", "ctrl+c":
+			m.action = ActionSnooze
+			return m, tea.Quit
+		}
+	}
+
+	// Scrolling keys and the mouse wheel move through the release notes
+	var cmd tea.Cmd
+	m.notes, cmd = m.notes.Update(msg)
+	return m, cmd
+}
+
+// resize fits the progress bar and the release notes to the terminal.
+func (m *model) resize() {
+	m.bar.Width = min(maxProgressWidth, max(10, m.width-30))
+	m.notes.Width = m.width
+	m.notes.Height = max(1, m.height-chromeHeight)
+	if m.info != nil {
+		m.setNotes()
+	}
+}
+
+// setNotes wraps the release notes to the terminal width and shows them in the viewport.
+func (m *model) setNotes() {
+	notes := strings.TrimRight(strings.ReplaceAll(m.info.ReleaseNotes, "\r\n", "\n"), "\n \t")
+	if notes == "" {
+		notes = "(no release notes)"
+	}
+	m.notes.SetContent(lipgloss.NewStyle().Width(m.width).Render(notes))
+}
+
+var (
+	headerStyle = lipgloss.NewStyle().Bold(true)
+	helpStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// View renders the current step of the flow.
+func (m model) View() string {
+	if !m.checked {
+		view := fmt.Sprintf("Checking for %s updates...\n", m.name)
+		if m.total > 0 {
+			view += "\n" + m.bar.ViewAs(downloadFraction(m.downloaded, m.total)) +
+				fmt.Sprintf("  %s / %s\n", formatBytes(m.downloaded), formatBytes(m.total))
+		}
+		return view
+	}
+	if m.info == nil || m.action != ActionNone {
+		return ""
+	}
+
+	header := headerStyle.Render(fmt.Sprintf("%s %s → %s", m.name, m.info.CurrentVersion, m.info.LatestVersion))
+	scroll := ""
+	if !m.notes.AtTop() || !m.notes.AtBottom() {
+		scroll = fmt.Sprintf("↑/↓ scroll (%d%%)  ", int(m.notes.ScrollPercent()*100))
+	}
+	help := helpStyle.Render(scroll + "[i] install  [s] skip  [l] later")
+	return header + "\n\n" + m.notes.View() + "\n\n" + help + "\n"
+}
+
+// downloadFraction returns the downloaded fraction of total, clamped to [0, 1]: an oversized body
+// is reported one byte past the expected total before the download fails.
+func downloadFraction(downloaded, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return min(1, max(0, float64(downloaded)/float64(total)))
+}
+
+// formatBytes formats a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
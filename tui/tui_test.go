@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asaidimu/ghupdate"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDownloadFraction(t *testing.T) {
+	tests := []struct {
+		downloaded, total int64
+		want              float64
+	}{
+		{0, 100, 0},
+		{50, 100, 0.5},
+		{100, 100, 1},
+		// downloadAsset reports one byte past the expected size for oversized bodies
+		{101, 100, 1},
+		{10, 0, 0},
+		{-1, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := downloadFraction(tt.downloaded, tt.total); got != tt.want {
+			t.Errorf("downloadFraction(%d, %d) = %v, want %v", tt.downloaded, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestModelOversizedProgress(t *testing.T) {
+	m := newModel(ghupdate.UpdateConfig{}, "myapp")
+	next, _ := m.Update(progressMsg{downloaded: 1025, total: 1024})
+	if view := next.View(); !strings.Contains(view, "1.0 KiB / 1.0 KiB") {
+		t.Errorf("View() = %q, want the downloaded and total sizes", view)
+	}
+}
+
+func TestModelKeys(t *testing.T) {
+	info := &ghupdate.UpdateInfo{CurrentVersion: "v1.0.0", LatestVersion: "v1.1.0", ReleaseNotes: "Fixes"}
+	tests := []struct {
+		key  tea.KeyMsg
+		want Action
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")}, ActionInstall},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, ActionInstall},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}, ActionSkip},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")}, ActionSnooze},
+		{tea.KeyMsg{Type: tea.KeyEsc}, ActionSnooze},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, ActionSnooze},
+	}
+	for _, tt := range tests {
+		m, _ := newModel(ghupdate.UpdateConfig{}, "myapp").Update(checkedMsg{info: info})
+		if view := m.View(); !strings.Contains(view, "v1.0.0 → v1.1.0") || !strings.Contains(view, "Fixes") {
+			t.Fatalf("View() = %q, want the version change and release notes", view)
+		}
+		m, cmd := m.Update(tt.key)
+		if got := m.(model).action; got != tt.want {
+			t.Errorf("key %q: action = %v, want %v", tt.key, got, tt.want)
+		}
+		if cmd == nil {
+			t.Errorf("key %q: want the program to quit", tt.key)
+		}
+	}
+}
+
+func TestModelCancelWhileChecking(t *testing.T) {
+	m, cmd := newModel(ghupdate.UpdateConfig{}, "myapp").Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if m.(model).err != ErrCancelled || cmd == nil {
+		t.Errorf("ctrl+c while checking: err = %v, want ErrCancelled and quit", m.(model).err)
+	}
+}
+
+func TestModelScrollsNotes(t *testing.T) {
+	notes := strings.Repeat("line\n", 100) + "last"
+	m, _ := newModel(ghupdate.UpdateConfig{}, "myapp").Update(checkedMsg{info: &ghupdate.UpdateInfo{ReleaseNotes: notes}})
+	if strings.Contains(m.View(), "last") {
+		t.Fatal("View() shows the end of long release notes before scrolling")
+	}
+	for range 10 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+	if !strings.Contains(m.View(), "last") {
+		t.Error("View() does not show the end of the release notes after scrolling to the bottom")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package ghupdate
+
+import "os"
+
+// currentUmask returns 0 on platforms without a umask.
+func currentUmask() os.FileMode {
+	return 0
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"bufio"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// currentUmask returns the process umask.
+// POSIX offers no way to read the umask without setting it, and setting it, even briefly, would
+// race with files created by other goroutines. Linux reports it in /proc/self/status; elsewhere a
+// probe file is created with all permission bits requested and the kernel-applied umask read back
+// from its mode.
+func currentUmask() os.FileMode {
+	if mask, ok := procUmask(); ok {
+		return mask
+	}
+
+	probe := filepath.Join(os.TempDir(), ".ghupdate-umask-probe-"+strconv.FormatUint(rand.Uint64(), 36))
+	f, err := os.OpenFile(probe, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0777)
+	if err != nil {
+		return 022
+	}
+	defer os.Remove(probe)
+	info, err := f.Stat()
+	f.Close()
+	if err != nil {
+		return 022
+	}
+	return 0777 &^ info.Mode().Perm()
+}
+
+// procUmask reads the umask from the "Umask:" line of /proc/self/status (Linux 4.7 and later).
+func procUmask() (os.FileMode, bool) {
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "Umask:")
+		if !ok {
+			continue
+		}
+		mask, err := strconv.ParseUint(strings.TrimSpace(value), 8, 32)
+		if err != nil {
+			return 0, false
+		}
+		return os.FileMode(mask), true
+	}
+	return 0, false
+}
@@ -0,0 +1,171 @@
+package ghupdate
+
+import (
+	"strings"
+	"testing"
+)
+
+// versionSeeds are versions covering releases, prereleases, build metadata, `git describe` output
+// and malformed input.
+var versionSeeds = []string{
+	"v1.2.3", "1.2.3", "v1.2.3-rc.1", "v1.2.3-rc.2", "v1.2.3-alpha", "v1.2.3+build.5",
+	"v1.2.3-4-gabc1234", "v1.2.3-4-gabc1234-dirty", "v1.2.3-dirty", "v0.0.1", "v10.0.0",
+	"v1.2", "v1", "", "v", "not-a-version", "v1.2.3-",
+}
+
+func FuzzSemverCompare(f *testing.F) {
+	for _, a := range versionSeeds {
+		for _, b := range versionSeeds {
+			f.Add(a, b, "v1.2.4")
+		}
+	}
+	f.Fuzz(func(t *testing.T, a, b, c string) {
+		ab := semverCompare(a, b)
+		if ab < -1 || ab > 1 {
+			t.Fatalf("semverCompare(%q, %q) = %d, want -1, 0 or 1", a, b, ab)
+		}
+		if ba := semverCompare(b, a); ba != -ab {
+			t.Fatalf("semverCompare(%q, %q) = %d but semverCompare(%q, %q) = %d", a, b, ab, b, a, ba)
+		}
+		if aa := semverCompare(a, a); aa != 0 {
+			t.Fatalf("semverCompare(%q, %q) = %d, want 0", a, a, aa)
+		}
+		if ab <= 0 && semverCompare(b, c) <= 0 && semverCompare(a, c) > 0 {
+			t.Fatalf("ordering of %q <= %q <= %q is not transitive", a, b, c)
+		}
+	})
+}
+
+func FuzzCompareVersions(f *testing.F) {
+	for _, a := range versionSeeds {
+		f.Add(a, "v1.2.3")
+	}
+	f.Fuzz(func(t *testing.T, a, b string) {
+		config := UpdateConfig{}
+		if got, want := compareVersions(config, a, b), semverCompare(a, b); got != want {
+			t.Fatalf("compareVersions(%q, %q) = %d, want semverCompare result %d", a, b, got, want)
+		}
+		if isNewerVersion(config, a, b) != (compareVersions(config, b, a) > 0) {
+			t.Fatalf("isNewerVersion(%q, %q) disagrees with compareVersions", a, b)
+		}
+	})
+}
+
+func TestSemverCompareOrdering(t *testing.T) {
+	ordered := []string{
+		"v1.2.3-alpha",
+		"v1.2.3-rc.1",
+		"v1.2.3-rc.2",
+		"v1.2.3",
+		"v1.2.3-1-gabc1234",
+		"v1.2.3-1-gabc1234-dirty",
+		"v1.2.3-3-gdef5678",
+		"v1.2.4",
+		"v10.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := semverCompare(ordered[i], ordered[j]); got != want {
+				t.Errorf("semverCompare(%q, %q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	for _, pair := range [][2]string{{"v1.2.3", "1.2.3"}, {"v1.2.3", "v1.2.3+build.5"}} {
+		if got := semverCompare(pair[0], pair[1]); got != 0 {
+			t.Errorf("semverCompare(%q, %q) = %d, want 0", pair[0], pair[1], got)
+		}
+	}
+}
+
+func FuzzBuildAssetName(f *testing.F) {
+	f.Add("myapp-{version}-{os}-{arch}{ext}", "v1.2.3", "linux", "amd64", "linux")
+	f.Add("myapp_{version_no_v}_{os}_{arch}.tar.gz", "v2.0.0", "windows", "x86_64", "windows")
+	f.Add("{os}{arch}{ext}{version}", "", "", "", "darwin")
+	f.Add("plain-name", "v1", "linux", "arm64", "linux")
+	f.Fuzz(func(t *testing.T, pattern, version, osName, archName, targetOS string) {
+		name := buildAssetName(pattern, version, osName, archName, targetOS)
+
+		if !strings.Contains(pattern, "{") {
+			if name != pattern {
+				t.Fatalf("buildAssetName(%q) = %q, want the pattern unchanged", pattern, name)
+			}
+			return
+		}
+
+		// With values free of braces, no known placeholder survives expansion, unless braces left
+		// around placeholders form one anew once they are replaced, as in "{{ext}arch}"
+		placeholders := []string{"{version}", "{version_no_v}", "{os}", "{arch}", "{ext}"}
+		rest := pattern
+		for _, placeholder := range placeholders {
+			rest = strings.ReplaceAll(rest, placeholder, "")
+		}
+		if strings.ContainsAny(version+osName+archName+rest, "{}") {
+			return
+		}
+		for _, placeholder := range placeholders {
+			if strings.Contains(name, placeholder) {
+				t.Fatalf("buildAssetName(%q) = %q still contains %s", pattern, name, placeholder)
+			}
+		}
+	})
+}
+
+func TestBuildAssetName(t *testing.T) {
+	tests := []struct {
+		pattern, version, osName, archName, targetOS, want string
+	}{
+		{"myapp-{version}-{os}-{arch}{ext}", "v1.2.3", "linux", "amd64", "linux", "myapp-v1.2.3-linux-amd64"},
+		{"myapp-{version}-{os}-{arch}{ext}", "v1.2.3", "windows", "amd64", "windows", "myapp-v1.2.3-windows-amd64.exe"},
+		{"myapp_{version_no_v}_{os}_{arch}", "v1.2.3", "Darwin", "arm64", "darwin", "myapp_1.2.3_Darwin_arm64"},
+		{"myapp_{version_no_v}", "1.2.3", "linux", "amd64", "linux", "myapp_1.2.3"},
+	}
+	for _, tt := range tests {
+		if got := buildAssetName(tt.pattern, tt.version, tt.osName, tt.archName, tt.targetOS); got != tt.want {
+			t.Errorf("buildAssetName(%q, %q, %q, %q, %q) = %q, want %q", tt.pattern, tt.version, tt.osName, tt.archName, tt.targetOS, got, tt.want)
+		}
+	}
+}
+
+func FuzzExpandPlaceholder(f *testing.F) {
+	f.Add("myapp-linux-{libc}", "myapp_{libc}.tar.gz", "gnu", "")
+	f.Add("myapp-{armversion}", "myapp", "v7", "v6")
+	f.Add("{x}{x}", "-{x}", "", "")
+	f.Fuzz(func(t *testing.T, name1, name2, value1, value2 string) {
+		const placeholder = "{x}"
+		names := []string{name1, name2}
+		values := []string{value1, value2}
+		expanded := expandPlaceholder(names, placeholder, values)
+
+		if len(expanded) != len(names)*len(values) {
+			t.Fatalf("expandPlaceholder returned %d names, want %d", len(expanded), len(names)*len(values))
+		}
+		for i, value := range values {
+			for j, name := range names {
+				got := expanded[i*len(names)+j]
+				if !strings.Contains(name, placeholder) && got != name {
+					t.Fatalf("expandPlaceholder changed %q without the placeholder to %q", name, got)
+				}
+				// Unless braces elsewhere could form the placeholder anew, it is gone after expansion
+				rest := strings.ReplaceAll(name, placeholder, "") + value
+				if !strings.ContainsAny(rest, "{}") && strings.Contains(got, placeholder) {
+					t.Fatalf("expandPlaceholder(%q, %q) = %q still contains the placeholder", name, value, got)
+				}
+			}
+		}
+	})
+}
+
+func TestExpandPlaceholder(t *testing.T) {
+	got := expandPlaceholder([]string{"myapp-linux-{libc}", "myapp_linux_{libc}.tar.gz"}, "{libc}", []string{"gnu", ""})
+	want := []string{"myapp-linux-gnu", "myapp_linux_gnu.tar.gz", "myapp-linux", "myapp_linux.tar.gz"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expandPlaceholder = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package ghupdate
+
+import (
+	"net/http"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the import path of this module, used to look up its version in the build info.
+const modulePath = "github.com/asaidimu/ghupdate"
+
+// Version returns the version of ghupdate linked into the running application (e.g., "v1.6.0"),
+// or "dev" when it is not recorded (e.g., when built inside this module or with a replace directive).
+// The version of the application itself is reported by ReadVersionInfo.
+func Version() string {
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	for _, dep := range build.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		if dep.Version != "" && dep.Version != "(devel)" {
+			return dep.Version
+		}
+	}
+	return "dev"
+}
+
+// userAgent returns the User-Agent sent with every request: config.UserAgent if set, otherwise
+// "<app>/<version> ghupdate/<library version>", where the application name is derived from
+// ExecutablePath (or GitHubRepo if unset) and the version from CurrentVersion.
+func userAgent(config UpdateConfig) string {
+	if config.UserAgent != "" {
+		return config.UserAgent
+	}
+
+	app := config.GitHubRepo
+	if config.ExecutablePath != "" {
+		app = strings.TrimSuffix(filepath.Base(config.ExecutablePath), ".exe")
+	}
+	ua := "ghupdate/" + Version()
+	if app == "" {
+		return ua
+	}
+	version := config.CurrentVersion
+	if version == "" {
+		version = "unknown"
+	}
+	return app + "/" + version + " " + ua
+}
+
+// setUserAgent sets the User-Agent header of req (see userAgent).
+func setUserAgent(config UpdateConfig, req *http.Request) {
+	req.Header.Set("User-Agent", userAgent(config))
+}
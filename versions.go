@@ -0,0 +1,200 @@
+package ghupdate
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionScheme selects one of the built-in version comparators.
+type VersionScheme string
+
+const (
+	// VersionSchemeSemver orders versions as semantic versions (SemverComparator). It is the default.
+	VersionSchemeSemver VersionScheme = "semver"
+	// VersionSchemeCalVer orders dotted numeric versions such as 2024.05.1 (CalVerComparator).
+	VersionSchemeCalVer VersionScheme = "calver"
+	// VersionSchemeBuildNumber orders plain integer build numbers such as 1042 (BuildNumberComparator).
+	VersionSchemeBuildNumber VersionScheme = "build"
+)
+
+// VersionComparator orders version strings. Implement it to use a different versioning library or
+// scheme than the built-in semantic versioning (e.g., a wrapper around Masterminds/semver or
+// hashicorp/go-version with their specific prerelease rules).
+type VersionComparator interface {
+	// Compare returns -1 if a is older than b, 0 if they are equal and +1 if a is newer than b.
+	Compare(a, b string) int
+}
+
+// VersionComparatorFunc adapts an ordinary comparison function to the VersionComparator interface.
+type VersionComparatorFunc func(a, b string) int
+
+// Compare calls f.
+func (f VersionComparatorFunc) Compare(a, b string) int {
+	return f(a, b)
+}
+
+// SemverComparator is the default comparator. It follows semver 2.0 as implemented by
+// golang.org/x/mod/semver and accepts versions with or without a leading "v". Build metadata is
+// ignored, and `git describe` versions ("v1.2.3-4-gabc123") are ordered after their tag.
+var SemverComparator VersionComparator = VersionComparatorFunc(semverCompare)
+
+// StrictSemverComparator follows semver 2.0 without special handling of `git describe` versions,
+// which are ordered as prereleases of the following release (see UpdateConfig.StrictPrereleaseOrdering).
+var StrictSemverComparator VersionComparator = VersionComparatorFunc(strictSemverCompare)
+
+// describeSuffix matches the suffix `git describe` appends to a tag for later or modified commits:
+// the number of commits since the tag and the abbreviated commit hash, and/or "-dirty".
+var describeSuffix = regexp.MustCompile(`(?:-([0-9]+)-g[0-9a-f]{4,40})?(-dirty)?$`)
+
+// CalVerComparator orders calendar versions such as 2024.05.1 or 2024.5.1-rc1, and other dotted
+// numeric versions such as four-part Windows file versions (1.2.3.4). Components are compared
+// numerically, so leading zeros do not matter and a missing component counts as zero. A suffix
+// after "-" marks a prerelease, which is older than the same version without a suffix. A leading
+// "v" is ignored.
+var CalVerComparator VersionComparator = VersionComparatorFunc(calverCompare)
+
+// BuildNumberComparator orders plain integer build numbers such as 1042. A non-numeric prefix
+// (e.g., "v", "r" or "build-") is ignored. Versions without a number are older than any numbered one.
+var BuildNumberComparator VersionComparator = VersionComparatorFunc(buildNumberCompare)
+
+// versionComparator returns the comparator configured in config (VersionComparator, then
+// CompareVersions, then VersionScheme), or SemverComparator.
+func versionComparator(config UpdateConfig) VersionComparator {
+	if config.VersionComparator != nil {
+		return config.VersionComparator
+	}
+	if config.CompareVersions != nil {
+		return VersionComparatorFunc(config.CompareVersions)
+	}
+	switch config.VersionScheme {
+	case VersionSchemeCalVer:
+		return CalVerComparator
+	case VersionSchemeBuildNumber:
+		return BuildNumberComparator
+	}
+	if config.StrictPrereleaseOrdering {
+		return StrictSemverComparator
+	}
+	return SemverComparator
+}
+
+// hasCustomComparator reports whether config replaces the built-in semantic version comparison.
+func hasCustomComparator(config UpdateConfig) bool {
+	return config.VersionComparator != nil || config.CompareVersions != nil ||
+		(config.VersionScheme != "" && config.VersionScheme != VersionSchemeSemver)
+}
+
+// validateVersionScheme checks that scheme names a built-in comparator.
+// It returns an error for unknown schemes.
+func validateVersionScheme(scheme VersionScheme) error {
+	switch scheme {
+	case "", VersionSchemeSemver, VersionSchemeCalVer, VersionSchemeBuildNumber:
+		return nil
+	}
+	return fmt.Errorf("unknown VersionScheme %q", scheme)
+}
+
+// splitDescribeVersion splits a `git describe` version into the tag it was derived from and the
+// number of commits made after it. A modified working tree ("-dirty") counts as one more commit.
+// Other versions are returned unchanged with a count of zero.
+func splitDescribeVersion(version string) (string, int) {
+	m := describeSuffix.FindStringSubmatchIndex(version)
+	if m[0] == len(version) {
+		return version, 0
+	}
+	base := version[:m[0]]
+	if base == "" || base == "v" || strings.Contains(base, "+") {
+		return version, 0
+	}
+	commits := 0
+	if m[2] >= 0 {
+		commits, _ = strconv.Atoi(version[m[2]:m[3]])
+	}
+	if m[4] >= 0 {
+		commits++
+	}
+	return base, commits
+}
+
+// calverCompare compares two dotted numeric versions with an optional "-" prerelease suffix.
+//
+// It returns -1 if a < b, 0 if a == b and +1 if a > b.
+func calverCompare(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	if c := compareDotted(aCore, bCore); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareDotted(aPre, bPre)
+}
+
+// compareDotted compares two dot-separated lists component by component. Numeric components are
+// compared as numbers and sort before non-numeric ones, which are compared lexically; missing
+// components count as zero.
+//
+// It returns -1 if a < b, 0 if a == b and +1 if a > b.
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		x, y := "0", "0"
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		xn, xErr := strconv.ParseUint(x, 10, 64)
+		yn, yErr := strconv.ParseUint(y, 10, 64)
+		var c int
+		switch {
+		case xErr == nil && yErr == nil:
+			c = cmp.Compare(xn, yn)
+		case xErr == nil:
+			c = -1
+		case yErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// buildNumberCompare compares two integer build numbers, ignoring any non-numeric prefix.
+//
+// It returns -1 if a < b, 0 if a == b and +1 if a > b.
+func buildNumberCompare(a, b string) int {
+	x, xErr := parseBuildNumber(a)
+	y, yErr := parseBuildNumber(b)
+	switch {
+	case xErr == nil && yErr == nil:
+		return cmp.Compare(x, y)
+	case xErr == nil:
+		return 1
+	case yErr == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// parseBuildNumber parses the integer following any non-numeric prefix of version.
+// It returns an error if version does not end in a number.
+func parseBuildNumber(version string) (uint64, error) {
+	digits := strings.TrimLeftFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	return strconv.ParseUint(digits, 10, 64)
+}
@@ -0,0 +1,155 @@
+package ghupdate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// watchdogFlag is the argument used to launch the application as a post-update watchdog.
+const watchdogFlag = "--ghupdate-watchdog"
+
+// probation describes an installed update that has not yet been reported healthy (see MarkHealthy).
+type probation struct {
+	// Version is the installed version.
+	Version string `json:"version"`
+	// FromVersion is the replaced version, restored if the update does not become healthy.
+	FromVersion string `json:"from_version"`
+	// Deadline is the time by which MarkHealthy must be called.
+	Deadline time.Time `json:"deadline"`
+}
+
+// MarkHealthy reports that the running version started successfully, ending the watch of the
+// post-update watchdog (see UpdateConfig.HealthTimeout). It is meant to be called once the
+// application is up and working, e.g. after it has opened its window or is serving requests, and is
+// a no-op when no update is being watched.
+//
+// It returns an error if the updater state in dataDir cannot be read or written.
+func MarkHealthy(dataDir string) error {
+	state, err := loadState(dataDir)
+	if err != nil || state.Probation == nil {
+		return err
+	}
+	return updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.Probation = nil
+	})
+}
+
+// startWatchdog puts the update from fromVersion to version installed at originalPath on probation
+// and starts a copy of the executable at exePath as its watchdog, watching the process pid. Nothing
+// is watched if fromVersion has not been kept for rollback.
+//
+// It returns an error if the watchdog cannot be started.
+func startWatchdog(exePath, dataDir, originalPath string, owner *Ownership, pid int, fromVersion, version string, timeout time.Duration) error {
+	if !processCheckSupported {
+		return nil
+	}
+	backups, err := ListBackups(dataDir)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(backups, func(b Backup) bool { return b.Version == fromVersion }) {
+		return nil
+	}
+
+	err = updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) {
+		state.Probation = &probation{
+			Version:     version,
+			FromVersion: fromVersion,
+			Deadline:    time.Now().Add(timeout).UTC(),
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		watchdogFlag,
+		"--pid=" + strconv.Itoa(pid),
+		"--original-path=" + originalPath,
+		"--data-dir=" + dataDir,
+	}
+	if owner != nil {
+		args = append(args, "--owner="+owner.String())
+	}
+	cmd := exec.Command(exePath, args...)
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) { state.Probation = nil })
+		return fmt.Errorf("failed to start update watchdog: %w", err)
+	}
+	return nil
+}
+
+// runWatchdog waits for the update on probation to be reported healthy. If the watched process
+// exits or the deadline passes first, the process is killed and the replaced version is restored.
+// It never returns.
+func runWatchdog(args []string) {
+	var pid int
+	var originalPath, dataDir string
+	var owner *Ownership
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--pid=") {
+			pid, _ = strconv.Atoi(strings.TrimPrefix(arg, "--pid="))
+		} else if strings.HasPrefix(arg, "--original-path=") {
+			originalPath = strings.TrimPrefix(arg, "--original-path=")
+		} else if strings.HasPrefix(arg, "--data-dir=") {
+			dataDir = strings.TrimPrefix(arg, "--data-dir=")
+		} else if strings.HasPrefix(arg, "--owner=") {
+			owner, _ = parseOwnership(strings.TrimPrefix(arg, "--owner="))
+		}
+	}
+	if pid == 0 || originalPath == "" || dataDir == "" {
+		fmt.Fprintf(os.Stderr, "Invalid watchdog arguments: original-path=%q, data-dir=%q, pid=%d\n", originalPath, dataDir, pid)
+		os.Exit(1)
+	}
+
+	var watched *probation
+	for {
+		state, err := loadState(dataDir)
+		if err == nil {
+			if state.Probation == nil || (watched != nil && *state.Probation != *watched) {
+				os.Exit(0) // Reported healthy, or superseded by another update
+			}
+			watched = state.Probation
+		}
+		if !isProcessRunning(pid) || (watched != nil && time.Now().After(watched.Deadline)) {
+			break
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	if watched == nil {
+		fmt.Fprintf(os.Stderr, "Failed to read the update on probation from %s\n", dataDir)
+		os.Exit(1)
+	}
+
+	// Give the process a last chance to report in before exiting
+	if state, err := loadState(dataDir); err == nil && (state.Probation == nil || *state.Probation != *watched) {
+		os.Exit(0)
+	}
+
+	if process, err := os.FindProcess(pid); err == nil && isProcessRunning(pid) {
+		process.Kill()
+		waitForProcessExit(pid, 10*time.Second)
+	}
+
+	failure := fmt.Errorf("%s did not report healthy by %s", watched.Version, watched.Deadline.Format(time.RFC3339))
+	config := UpdateConfig{
+		DataDir:        dataDir,
+		ExecutablePath: originalPath,
+		CurrentVersion: watched.Version,
+		Owner:          owner,
+	}
+	recordFailure(config, watched.Version, failure)
+	updateState(UpdateConfig{DataDir: dataDir}, func(state *updaterState) { state.Probation = nil })
+	if err := RestoreBackup(config, watched.FromVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to roll back to %s: %v\n", watched.FromVersion, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Rolled back to %s: %v\n", watched.FromVersion, failure)
+	os.Exit(0)
+}
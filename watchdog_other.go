@@ -0,0 +1,8 @@
+//go:build !unix
+
+package ghupdate
+
+import "os/exec"
+
+// detachProcess leaves cmd attached on platforms without sessions.
+func detachProcess(cmd *exec.Cmd) {}
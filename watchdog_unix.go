@@ -0,0 +1,14 @@
+//go:build unix
+
+package ghupdate
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachProcess starts cmd in a new session, so that it outlives the terminal or process group
+// of the application.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
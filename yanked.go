@@ -0,0 +1,83 @@
+package ghupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultYankedAssetName is the release asset holding the list of yanked versions when
+// UpdateConfig.YankedListURL is empty. It is read from the newest release of the followed channel.
+const DefaultYankedAssetName = "yanked.json"
+
+// ErrReleaseYanked is returned by UpdateToVersion when the requested version has been yanked by the publisher.
+var ErrReleaseYanked = errors.New("release has been yanked")
+
+// YankedRelease is an entry of a publisher's list of versions that must never be installed. The list
+// is a JSON document of the form:
+//
+//	{"yanked": [{"version": "v1.4.0", "reason": "corrupts the database on upgrade"}]}
+type YankedRelease struct {
+	// Version is the yanked version, compared with the configured version comparator.
+	Version string `json:"version"`
+	// Reason optionally explains why the version was yanked.
+	Reason string `json:"reason,omitempty"`
+}
+
+// yankedList is the JSON document listing yanked releases.
+type yankedList struct {
+	Yanked []YankedRelease `json:"yanked"`
+}
+
+// fetchYankedReleases fetches the publisher's list of yanked versions from config.YankedListURL, or
+// from the DefaultYankedAssetName asset of latest, the newest release of the followed channel. If
+// latest is nil, it is fetched when needed. A missing asset means nothing has been yanked.
+//
+// It returns an error if the list cannot be fetched or decoded.
+func fetchYankedReleases(config UpdateConfig, latest *GitHubRelease) ([]YankedRelease, error) {
+	var data []byte
+	if config.YankedListURL != "" {
+		listURL := strings.NewReplacer("{owner}", config.GitHubOwner, "{repo}", config.GitHubRepo).Replace(config.YankedListURL)
+		err := withRetry(config, func() error {
+			var err error
+			data, err = fetchAssetBytes(context.Background(), config, listURL)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch yanked releases: %w", err)
+		}
+	} else {
+		if latest == nil {
+			var err error
+			if latest, err = fetchChannelRelease(config); err != nil {
+				return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+			}
+		}
+		matches := matchAssets(latest.Assets, DefaultYankedAssetName)
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		var err error
+		if data, err = fetchReleaseAssetBytes(context.Background(), config, &matches[0]); err != nil {
+			return nil, fmt.Errorf("failed to fetch yanked releases: %w", err)
+		}
+	}
+
+	var list yankedList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode yanked releases: %w", err)
+	}
+	return list.Yanked, nil
+}
+
+// findYanked returns the entry of yanked matching version, or nil if it has not been yanked.
+func findYanked(config UpdateConfig, yanked []YankedRelease, version string) *YankedRelease {
+	for i := range yanked {
+		if compareVersions(config, yanked[i].Version, version) == 0 {
+			return &yanked[i]
+		}
+	}
+	return nil
+}